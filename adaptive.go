@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+	_ "time/tzdata" // the ECI timezone, also in images without zoneinfo.
+)
+
+// maxUpdateObservations is the number of observed updates that are used to learn the update window.
+const maxUpdateObservations = 14
+
+// PollPolicy decides when an initiative is polled next.
+type PollPolicy interface {
+	Next(registrationNumber RegistrationNumber, polled time.Time) time.Time
+}
+
+// AdaptivePolicy is a [PollPolicy] that learns at what time of day the ECI changes the UpdateDate of an initiative.
+// Around that time it polls every Interval, otherwise it waits for the next window, but never longer than
+// MaxStaleness. Until an update has been observed it polls every Interval.
+//
+// It implements [Sink] to observe the reports.
+type AdaptivePolicy struct {
+	Interval time.Duration
+	// Window is the width of the time span around the expected update in which is polled every Interval.
+	Window       time.Duration
+	MaxStaleness time.Duration
+
+	mu          sync.Mutex
+	initiatives map[RegistrationNumber]*updateHistory
+	location    *time.Location
+}
+
+type updateHistory struct {
+	updateDate string
+	// updatedOn is the day on which the UpdateDate last changed.
+	updatedOn string
+	// observed are the times of day at which a changed UpdateDate was seen.
+	observed []time.Duration
+}
+
+// NewAdaptivePolicy creates an [AdaptivePolicy]. The times of day are in the timezone of the ECI.
+func NewAdaptivePolicy(interval, window, maxStaleness time.Duration) *AdaptivePolicy {
+	location, err := time.LoadLocation("Europe/Brussels")
+	if err != nil {
+		location = time.UTC
+	}
+
+	return &AdaptivePolicy{
+		Interval:     interval,
+		Window:       window,
+		MaxStaleness: maxStaleness,
+		initiatives:  map[RegistrationNumber]*updateHistory{},
+		location:     location,
+	}
+}
+
+// Write implements [Sink] and records the time of day at which the UpdateDate changed.
+func (p *AdaptivePolicy) Write(_ context.Context, registrationNumber RegistrationNumber, report *ProgressResponse) error {
+	p.Observe(registrationNumber, report.SOSReport.UpdateDate, time.Now())
+
+	return nil
+}
+
+// Observe records the UpdateDate seen at the given time.
+func (p *AdaptivePolicy) Observe(registrationNumber RegistrationNumber, updateDate string, at time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	h, ok := p.initiatives[registrationNumber]
+	if !ok {
+		// The first report only tells which date is current, not when it changed.
+		p.initiatives[registrationNumber] = &updateHistory{updateDate: updateDate}
+
+		return
+	}
+
+	if h.updateDate == updateDate {
+		return
+	}
+
+	local := at.In(p.location)
+
+	h.updateDate = updateDate
+	h.updatedOn = local.Format(time.DateOnly)
+	h.observed = append(h.observed, sinceMidnight(local))
+
+	if len(h.observed) > maxUpdateObservations {
+		h.observed = h.observed[len(h.observed)-maxUpdateObservations:]
+	}
+}
+
+// Forget drops what was learned about the initiative, so a removed initiative does not leak its history and starts
+// over when it is added again.
+func (p *AdaptivePolicy) Forget(registrationNumber RegistrationNumber) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.initiatives, registrationNumber)
+}
+
+// ExpectedUpdate returns the learned time of day of the update of the initiative.
+func (p *AdaptivePolicy) ExpectedUpdate(registrationNumber RegistrationNumber) (time.Duration, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	h, ok := p.initiatives[registrationNumber]
+	if !ok || len(h.observed) == 0 {
+		return 0, false
+	}
+
+	observed := append([]time.Duration(nil), h.observed...)
+	sort.Slice(observed, func(i, j int) bool { return observed[i] < observed[j] })
+
+	return observed[len(observed)/2], true
+}
+
+// Next implements [PollPolicy].
+func (p *AdaptivePolicy) Next(registrationNumber RegistrationNumber, polled time.Time) time.Time {
+	latest := polled.Add(p.MaxStaleness)
+	fast := polled.Add(p.Interval)
+
+	expected, ok := p.ExpectedUpdate(registrationNumber)
+	if !ok {
+		return fast
+	}
+
+	local := polled.In(p.location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, p.location)
+	windowStart := midnight.Add(expected - p.Window/2) //nolint:mnd // centred around the expected update.
+	windowEnd := windowStart.Add(p.Window)
+
+	p.mu.Lock()
+	updatedToday := p.initiatives[registrationNumber].updatedOn == local.Format(time.DateOnly)
+	p.mu.Unlock()
+
+	switch {
+	case updatedToday || !polled.Before(windowEnd):
+		// Wait for the window of the next day.
+		return earliest(windowStart.AddDate(0, 0, 1), latest)
+	case fast.Before(windowStart):
+		return earliest(windowStart, latest)
+	default:
+		return earliest(fast, latest)
+	}
+}
+
+func earliest(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+
+	return b
+}
+
+func sinceMidnight(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
@@ -0,0 +1,128 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	eci "github.com/tvanriel/eci-prometheus-exporter"
+)
+
+func TestAdaptivePolicy_Next(t *testing.T) {
+	t.Parallel()
+
+	brussels, err := time.LoadLocation("Europe/Brussels")
+	require.NoError(t, err)
+
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, 6, day, hour, minute, 0, 0, brussels)
+	}
+
+	rn := *MustParseRegistrationNumber("ECI(2024)000007")
+
+	// learned observes updates at 10:00, 10:20 and 10:10 on three days, so the window is 09:10 to 11:10.
+	learned := func(p *eci.AdaptivePolicy) {
+		p.Observe(rn, "01/06/2025", at(1, 12, 0))
+		p.Observe(rn, "02/06/2025", at(2, 10, 0))
+		p.Observe(rn, "03/06/2025", at(3, 10, 20))
+		p.Observe(rn, "04/06/2025", at(4, 10, 10))
+	}
+
+	tests := map[string]struct {
+		setup  func(p *eci.AdaptivePolicy)
+		polled time.Time
+		want   time.Time
+	}{
+		"nothing learned": {
+			setup:  func(p *eci.AdaptivePolicy) { p.Observe(rn, "01/06/2025", at(1, 12, 0)) },
+			polled: at(2, 3, 0),
+			want:   at(2, 3, 5),
+		},
+		"before the window": {
+			setup:  learned,
+			polled: at(5, 7, 0),
+			want:   at(5, 9, 10),
+		},
+		"inside the window": {
+			setup:  learned,
+			polled: at(5, 9, 30),
+			want:   at(5, 9, 35),
+		},
+		"after the window": {
+			setup:  learned,
+			polled: at(5, 12, 0),
+			want:   at(5, 18, 0),
+		},
+		"updated today": {
+			setup: func(p *eci.AdaptivePolicy) {
+				learned(p)
+				p.Observe(rn, "05/06/2025", at(5, 9, 40))
+			},
+			polled: at(5, 9, 45),
+			want:   at(5, 15, 45),
+		},
+		"next window within the maximum staleness": {
+			setup:  learned,
+			polled: at(5, 23, 0),
+			want:   at(6, 5, 0),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p := eci.NewAdaptivePolicy(5*time.Minute, 2*time.Hour, 6*time.Hour)
+			tt.setup(p)
+
+			assert.Equal(t, tt.want.UTC(), p.Next(rn, tt.polled).UTC())
+		})
+	}
+}
+
+func TestAdaptivePolicy_ExpectedUpdate(t *testing.T) {
+	t.Parallel()
+
+	rn := *MustParseRegistrationNumber("ECI(2024)000007")
+	p := eci.NewAdaptivePolicy(5*time.Minute, 2*time.Hour, 6*time.Hour)
+
+	_, ok := p.ExpectedUpdate(rn)
+	assert.False(t, ok)
+
+	p.Observe(rn, "01/06/2025", time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC))
+	p.Observe(rn, "01/06/2025", time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC))
+
+	_, ok = p.ExpectedUpdate(rn)
+	assert.False(t, ok, "the first report and unchanged dates are no updates")
+
+	p.Observe(rn, "02/06/2025", time.Date(2025, 6, 2, 8, 30, 0, 0, time.UTC))
+
+	expected, ok := p.ExpectedUpdate(rn)
+	require.True(t, ok)
+	assert.Equal(t, 10*time.Hour+30*time.Minute, expected, "08:30 UTC is 10:30 in Brussels")
+}
+
+func TestAdaptivePolicy_Forget(t *testing.T) {
+	t.Parallel()
+
+	rn := *MustParseRegistrationNumber("ECI(2024)000007")
+	p := eci.NewAdaptivePolicy(5*time.Minute, 2*time.Hour, 6*time.Hour)
+
+	p.Observe(rn, "01/06/2025", time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC))
+	p.Observe(rn, "02/06/2025", time.Date(2025, 6, 2, 8, 30, 0, 0, time.UTC))
+
+	_, ok := p.ExpectedUpdate(rn)
+	require.True(t, ok)
+
+	p.Forget(rn)
+
+	_, ok = p.ExpectedUpdate(rn)
+	assert.False(t, ok)
+
+	p.Observe(rn, "03/06/2025", time.Date(2025, 6, 3, 8, 0, 0, 0, time.UTC))
+
+	_, ok = p.ExpectedUpdate(rn)
+	assert.False(t, ok, "the first report after forgetting is no update")
+}
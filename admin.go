@@ -0,0 +1,242 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// adminMaxBodySize is the maximum size of an admin API request body.
+const adminMaxBodySize = 4 << 10
+
+// AdminAPIPrefix is the path prefix that requires the admin credentials of the web config.
+const AdminAPIPrefix = "/api/"
+
+// ErrAdminAPIUnauthenticated is returned when the admin API is enabled without admin credentials.
+var ErrAdminAPIUnauthenticated = errors.New(
+	"the admin API requires admin_basic_auth_users or admin_bearer_tokens in the web config")
+
+// TrackedInitiatives returns the initiatives that are currently polled.
+func (a *Application) TrackedInitiatives() []RegistrationNumber {
+	a.initiativesMu.RLock()
+	defer a.initiativesMu.RUnlock()
+
+	return slices.Clone(a.Initiatives)
+}
+
+// Tracked reports whether the initiative is currently polled.
+func (a *Application) Tracked(registrationNumber RegistrationNumber) bool {
+	a.initiativesMu.RLock()
+	defer a.initiativesMu.RUnlock()
+
+	return slices.Contains(a.Initiatives, registrationNumber)
+}
+
+// AddInitiative starts polling the initiative. It returns false when the initiative is already polled.
+func (a *Application) AddInitiative(registrationNumber RegistrationNumber) bool {
+	a.initiativesMu.Lock()
+
+	if slices.Contains(a.Initiatives, registrationNumber) {
+		a.initiativesMu.Unlock()
+
+		return false
+	}
+
+	a.Initiatives = append(a.Initiatives, registrationNumber)
+	a.initiativesMu.Unlock()
+
+	if a.Scheduler != nil {
+		a.Scheduler.Add(registrationNumber)
+	}
+
+	return true
+}
+
+// RemoveInitiative stops polling the initiative and deletes its series. It returns false when the initiative is not
+// polled.
+func (a *Application) RemoveInitiative(registrationNumber RegistrationNumber) bool {
+	a.initiativesMu.Lock()
+
+	i := slices.Index(a.Initiatives, registrationNumber)
+	if i < 0 {
+		a.initiativesMu.Unlock()
+
+		return false
+	}
+
+	a.Initiatives = slices.Delete(a.Initiatives, i, i+1)
+	a.initiativesMu.Unlock()
+
+	if a.Scheduler != nil {
+		a.Scheduler.Remove(registrationNumber)
+	}
+
+	a.deleteSeries(registrationNumber)
+
+	return true
+}
+
+// deleteSeries forgets everything the exporter knows about the initiative.
+func (a *Application) deleteSeries(registrationNumber RegistrationNumber) {
+	labels := prometheus.Labels{"initiative_id": registrationNumber.String()}
+
+	for _, vec := range a.initiativeVecs() {
+		vec.DeletePartialMatch(labels)
+	}
+
+	a.Reports.Delete(registrationNumber)
+	a.Cache.Delete(registrationNumber)
+	a.Breakers.Delete(registrationNumber)
+
+	if a.Scheduler != nil {
+		if policy, ok := a.Scheduler.Policy.(*AdaptivePolicy); ok {
+			policy.Forget(registrationNumber)
+		}
+	}
+
+	a.previousMu.Lock()
+	delete(a.previousTotals, registrationNumber)
+	a.previousMu.Unlock()
+}
+
+// AdminInitiative is the request body of the admin initiatives endpoint.
+type AdminInitiative struct {
+	InitiativeID RegistrationNumber `json:"initiative_id"`
+}
+
+// AdminInitiatives is the response of the admin initiatives endpoint.
+type AdminInitiatives struct {
+	Initiatives []string `json:"initiatives"`
+}
+
+// AdminAPI serves /api/v1/admin/initiatives, which lists (GET), adds (POST) and removes (DELETE) the polled
+// initiatives. Changes are persisted in State, so they survive restarts.
+type AdminAPI struct {
+	App   *Application
+	State *State
+
+	mu  sync.Mutex
+	mux *http.ServeMux
+}
+
+// NewAdminAPI creates the admin API of the application.
+func NewAdminAPI(a *Application, state *State) *AdminAPI {
+	api := &AdminAPI{App: a, State: state, mux: http.NewServeMux()}
+
+	api.mux.HandleFunc("GET /api/v1/admin/initiatives", api.list)
+	api.mux.HandleFunc("POST /api/v1/admin/initiatives", api.add)
+	api.mux.HandleFunc("DELETE /api/v1/admin/initiatives", api.remove)
+
+	return api
+}
+
+// ServeHTTP implements [http.Handler].
+func (api *AdminAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	api.mux.ServeHTTP(w, r)
+}
+
+func (api *AdminAPI) list(w http.ResponseWriter, _ *http.Request) {
+	api.respond(w, http.StatusOK)
+}
+
+func (api *AdminAPI) add(w http.ResponseWriter, r *http.Request) {
+	rn, ok := api.initiative(w, r)
+	if !ok {
+		return
+	}
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	if api.App.Tracked(*rn) {
+		api.respond(w, http.StatusOK)
+
+		return
+	}
+
+	err := api.State.AddInitiative(rn.String())
+	if err != nil {
+		api.App.Logger.Error("Cannot persist added initiative", zap.Error(err))
+		http.Error(w, "cannot persist initiative", http.StatusInternalServerError)
+
+		return
+	}
+
+	api.App.AddInitiative(*rn)
+	api.App.Logger.Info("Added initiative", zap.String("initiative_id", rn.String()))
+	api.respond(w, http.StatusCreated)
+}
+
+func (api *AdminAPI) remove(w http.ResponseWriter, r *http.Request) {
+	rn, ok := api.initiative(w, r)
+	if !ok {
+		return
+	}
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	if !api.App.Tracked(*rn) {
+		http.Error(w, "initiative "+rn.String()+" is not tracked", http.StatusNotFound)
+
+		return
+	}
+
+	err := api.State.RemoveInitiative(rn.String())
+	if err != nil {
+		api.App.Logger.Error("Cannot persist removed initiative", zap.Error(err))
+		http.Error(w, "cannot persist initiative", http.StatusInternalServerError)
+
+		return
+	}
+
+	api.App.RemoveInitiative(*rn)
+	api.App.Logger.Info("Removed initiative", zap.String("initiative_id", rn.String()))
+	api.respond(w, http.StatusOK)
+}
+
+// initiative reads the initiative of the request body and responds with 400 when it is invalid.
+func (api *AdminAPI) initiative(w http.ResponseWriter, r *http.Request) (*RegistrationNumber, bool) {
+	var body AdminInitiative
+
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, adminMaxBodySize)).Decode(&body)
+	if err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+
+		return nil, false
+	}
+
+	if body.InitiativeID == (RegistrationNumber{}) {
+		http.Error(w, "missing initiative_id", http.StatusBadRequest)
+
+		return nil, false
+	}
+
+	return &body.InitiativeID, true
+}
+
+func (api *AdminAPI) respond(w http.ResponseWriter, status int) {
+	tracked := api.App.TrackedInitiatives()
+	response := AdminInitiatives{Initiatives: make([]string, 0, len(tracked))}
+
+	for _, rn := range tracked {
+		response.Initiatives = append(response.Initiatives, rn.String())
+	}
+
+	slices.Sort(response.Initiatives)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	err := json.NewEncoder(w).Encode(response)
+	if err != nil {
+		api.App.Logger.Debug("Cannot write admin response")
+	}
+}
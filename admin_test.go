@@ -0,0 +1,213 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	eci "github.com/tvanriel/eci-prometheus-exporter"
+	"go.uber.org/zap/zaptest"
+	"golang.org/x/time/rate"
+)
+
+func TestAdminAPI(t *testing.T) {
+	t.Parallel()
+
+	rn := MustParseRegistrationNumber("ECI(2024)000007")
+	server := ServerWantsCallForInitiativeID(rn)(t)
+
+	defer server.Close()
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	state, err := eci.LoadState(statePath)
+	require.NoError(t, err)
+
+	app := eci.NewApplication(zaptest.NewLogger(t), server.URL, []eci.RegistrationNumber{*rn}, "", http.DefaultClient)
+	require.NoError(t, app.FetchAndUpdateMetrics(t.Context(), *rn))
+	require.Equal(t, 1, testutil.CollectAndCount(app.LastSuccess))
+
+	api := eci.NewAdminAPI(app, state)
+
+	steps := []struct {
+		method string
+		body   string
+		want   int
+		tracks []string
+	}{
+		{method: http.MethodGet, want: http.StatusOK, tracks: []string{"ECI(2024)000007"}},
+		{
+			method: http.MethodPost,
+			body:   `{"initiative_id":"ECI(2025)000001"}`,
+			want:   http.StatusCreated,
+			tracks: []string{"ECI(2024)000007", "ECI(2025)000001"},
+		},
+		{
+			method: http.MethodPost,
+			body:   `{"initiative_id":"ECI(2025)000001"}`,
+			want:   http.StatusOK,
+			tracks: []string{"ECI(2024)000007", "ECI(2025)000001"},
+		},
+		{method: http.MethodPost, body: `{"initiative_id":"not an initiative"}`, want: http.StatusBadRequest},
+		{method: http.MethodPost, body: `{`, want: http.StatusBadRequest},
+		{
+			method: http.MethodDelete,
+			body:   `{"initiative_id":"ECI(2024)000007"}`,
+			want:   http.StatusOK,
+			tracks: []string{"ECI(2025)000001"},
+		},
+		{method: http.MethodDelete, body: `{"initiative_id":"ECI(2024)000007"}`, want: http.StatusNotFound},
+		{method: http.MethodPut, body: `{}`, want: http.StatusMethodNotAllowed},
+	}
+	for _, step := range steps {
+		rec := httptest.NewRecorder()
+		api.ServeHTTP(rec, httptest.NewRequest(step.method, "/api/v1/admin/initiatives", strings.NewReader(step.body)))
+
+		require.Equal(t, step.want, rec.Code, "%s %s: %s", step.method, step.body, rec.Body)
+
+		if step.tracks == nil {
+			continue
+		}
+
+		var got eci.AdminInitiatives
+
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+		assert.Equal(t, step.tracks, got.Initiatives, "%s %s", step.method, step.body)
+	}
+
+	assert.Equal(t, 0, testutil.CollectAndCount(app.LastSuccess), "series of the removed initiative are deleted")
+	assert.Empty(t, app.Reports.Snapshot())
+
+	restored, err := eci.LoadState(statePath)
+	require.NoError(t, err)
+
+	initiatives, err := restored.Initiatives([]eci.RegistrationNumber{*rn})
+	require.NoError(t, err)
+	assert.Equal(t, []eci.RegistrationNumber{*MustParseRegistrationNumber("ECI(2025)000001")}, initiatives)
+}
+
+func TestApplication_RemoveInitiativeDeletesAllSeries(t *testing.T) {
+	t.Parallel()
+
+	rn := MustParseRegistrationNumber("ECI(2024)000007")
+	server := ServerWantsCallForInitiativeID(rn)(t)
+
+	defer server.Close()
+
+	app := eci.NewApplication(zaptest.NewLogger(t), server.URL, []eci.RegistrationNumber{*rn}, "", http.DefaultClient)
+	app.Scheduler = eci.NewScheduler(app.TrackedInitiatives(), time.Hour, 1, rate.Inf, app.FetchAndUpdateMetrics)
+
+	registry := prometheus.NewRegistry()
+	app.MustRegisterWith(registry)
+	app.Scheduler.MustRegisterWith(registry)
+
+	require.NoError(t, app.FetchAndUpdateMetrics(t.Context(), *rn))
+
+	// Series that a successful poll does not create.
+	app.SignatureDecreases.WithLabelValues(rn.String(), "NL").Inc()
+	app.SignatureDecreaseMagnitude.WithLabelValues(rn.String(), "NL").Add(10)
+	app.ReportCountryIssues.WithLabelValues(rn.String(), "XX", "unknown").Set(1)
+	app.FetchFailures.WithLabelValues(rn.String()).Inc()
+	app.CacheHits.WithLabelValues(rn.String(), "etag").Inc()
+	app.Breakers.State.WithLabelValues(rn.String()).Set(0)
+
+	scrape := func() string {
+		rec := httptest.NewRecorder()
+		promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		return rec.Body.String()
+	}
+
+	label := `initiative_id="ECI(2024)000007"`
+	require.Contains(t, scrape(), label)
+
+	require.True(t, app.RemoveInitiative(*rn))
+
+	for _, line := range strings.Split(scrape(), "\n") {
+		assert.NotContains(t, line, label)
+	}
+}
+
+func TestApplication_RemoveInitiativeForgetsUpdateTimes(t *testing.T) {
+	t.Parallel()
+
+	rn := *MustParseRegistrationNumber("ECI(2024)000007")
+	app := eci.NewApplication(zaptest.NewLogger(t), "http://localhost", []eci.RegistrationNumber{rn}, "", http.DefaultClient)
+	app.Scheduler = eci.NewScheduler(app.TrackedInitiatives(), time.Hour, 1, rate.Inf, app.FetchAndUpdateMetrics)
+
+	policy := eci.NewAdaptivePolicy(5*time.Minute, 2*time.Hour, 6*time.Hour)
+	app.Scheduler.Policy = policy
+
+	policy.Observe(rn, "01/06/2025", time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC))
+	policy.Observe(rn, "02/06/2025", time.Date(2025, 6, 2, 8, 30, 0, 0, time.UTC))
+
+	require.True(t, app.RemoveInitiative(rn))
+
+	_, ok := policy.ExpectedUpdate(rn)
+	assert.False(t, ok, "a re-added initiative learns its update time again")
+}
+
+func TestState_Initiatives(t *testing.T) {
+	t.Parallel()
+
+	a := *MustParseRegistrationNumber("ECI(2024)000007")
+	b := *MustParseRegistrationNumber("ECI(2025)000001")
+
+	tests := map[string]struct {
+		change func(s *eci.State) error
+		want   []eci.RegistrationNumber
+	}{
+		"unchanged": {
+			change: func(*eci.State) error { return nil },
+			want:   []eci.RegistrationNumber{a},
+		},
+		"added": {
+			change: func(s *eci.State) error { return s.AddInitiative(b.String()) },
+			want:   []eci.RegistrationNumber{a, b},
+		},
+		"added twice": {
+			change: func(s *eci.State) error {
+				_ = s.AddInitiative(a.String())
+
+				return s.AddInitiative(a.String())
+			},
+			want: []eci.RegistrationNumber{a},
+		},
+		"removed": {
+			change: func(s *eci.State) error { return s.RemoveInitiative(a.String()) },
+			want:   []eci.RegistrationNumber{},
+		},
+		"removed and added again": {
+			change: func(s *eci.State) error {
+				_ = s.RemoveInitiative(a.String())
+
+				return s.AddInitiative(a.String())
+			},
+			want: []eci.RegistrationNumber{a},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			state, err := eci.LoadState("")
+			require.NoError(t, err)
+			require.NoError(t, tt.change(state))
+
+			got, err := state.Initiatives([]eci.RegistrationNumber{a})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,250 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// BreakerState is the state of a [CircuitBreaker]. The values are exposed as the breaker state metric.
+type BreakerState int
+
+// States of a [CircuitBreaker].
+const (
+	BreakerClosed BreakerState = iota
+	BreakerHalfOpen
+	BreakerOpen
+)
+
+// MetricBreakerState is the name of the circuit breaker state metric.
+const MetricBreakerState = "eci_circuit_breaker_state"
+
+// String implements [fmt.Stringer].
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerHalfOpen:
+		return "half-open"
+	case BreakerOpen:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (s BreakerState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// ErrUnknownBreakerState is returned when a breaker state cannot be parsed.
+var ErrUnknownBreakerState = errors.New("unknown breaker state")
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (s *BreakerState) UnmarshalText(text []byte) error {
+	for _, state := range []BreakerState{BreakerClosed, BreakerHalfOpen, BreakerOpen} {
+		if state.String() == string(text) {
+			*s = state
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %q", ErrUnknownBreakerState, text)
+}
+
+// ErrCircuitOpen is returned instead of calling the ECI API while the circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// CircuitBreaker stops calling the ECI API for an initiative after Threshold consecutive failures. After Cooldown
+// a single request is let through; it closes the breaker when it succeeds and opens it again when it fails.
+type CircuitBreaker struct {
+	Threshold int
+	Cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// Allow returns [ErrCircuitOpen] when no request may be made.
+func (b *CircuitBreaker) Allow(now time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerClosed:
+		return nil
+	case BreakerOpen:
+		if now.Sub(b.openedAt) < b.Cooldown {
+			return ErrCircuitOpen
+		}
+
+		b.state = BreakerHalfOpen
+		b.probing = true
+
+		return nil
+	default:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+
+		b.probing = true
+
+		return nil
+	}
+}
+
+// Success records a successful request and closes the breaker.
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = BreakerClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// Failure records a failed request and opens the breaker after Threshold consecutive failures or a failed probe.
+func (b *CircuitBreaker) Failure(now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+
+	if b.state == BreakerHalfOpen || (b.Threshold > 0 && b.failures >= b.Threshold) {
+		b.state = BreakerOpen
+		b.openedAt = now
+	}
+}
+
+// State returns the state and the number of consecutive failures.
+func (b *CircuitBreaker) State() (BreakerState, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state, b.failures
+}
+
+// Breakers holds a [CircuitBreaker] per initiative and exposes their state in the State metric. A Threshold of zero
+// disables the breakers, and nil Breakers allow every request.
+type Breakers struct {
+	Threshold int
+	Cooldown  time.Duration
+	State     *prometheus.GaugeVec
+
+	mu       sync.Mutex
+	breakers map[RegistrationNumber]*CircuitBreaker
+}
+
+// NewBreakers creates the breakers for the given initiatives.
+func NewBreakers(threshold int, cooldown time.Duration, initiatives []RegistrationNumber) *Breakers {
+	b := &Breakers{
+		Threshold: threshold,
+		Cooldown:  cooldown,
+		State: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: MetricBreakerState,
+			Help: "State of the circuit breaker around the ECI API per initiative (0 closed, 1 half-open, 2 open)",
+		}, []string{"initiative_id"}),
+		breakers: map[RegistrationNumber]*CircuitBreaker{},
+	}
+
+	for _, rn := range initiatives {
+		b.get(rn)
+	}
+
+	return b
+}
+
+func (b *Breakers) get(registrationNumber RegistrationNumber) *CircuitBreaker {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	breaker, ok := b.breakers[registrationNumber]
+	if !ok {
+		breaker = &CircuitBreaker{Threshold: b.Threshold, Cooldown: b.Cooldown}
+		b.breakers[registrationNumber] = breaker
+		b.State.WithLabelValues(registrationNumber.String()).Set(float64(BreakerClosed))
+	}
+
+	return breaker
+}
+
+// Allow returns [ErrCircuitOpen] when the ECI API must not be called for the initiative.
+func (b *Breakers) Allow(registrationNumber RegistrationNumber) error {
+	if b == nil || b.Threshold <= 0 {
+		return nil
+	}
+
+	breaker := b.get(registrationNumber)
+	err := breaker.Allow(time.Now())
+	b.observe(registrationNumber, breaker)
+
+	return err
+}
+
+// Success records a successful request for the initiative.
+func (b *Breakers) Success(registrationNumber RegistrationNumber) {
+	if b == nil || b.Threshold <= 0 {
+		return
+	}
+
+	breaker := b.get(registrationNumber)
+	breaker.Success()
+	b.observe(registrationNumber, breaker)
+}
+
+// Failure records a failed request for the initiative.
+func (b *Breakers) Failure(registrationNumber RegistrationNumber) {
+	if b == nil || b.Threshold <= 0 {
+		return
+	}
+
+	breaker := b.get(registrationNumber)
+	breaker.Failure(time.Now())
+	b.observe(registrationNumber, breaker)
+}
+
+// Delete forgets the breaker of the initiative.
+func (b *Breakers) Delete(registrationNumber RegistrationNumber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.breakers, registrationNumber)
+	b.State.DeleteLabelValues(registrationNumber.String())
+}
+
+// BreakerStatus is the state of the breaker of an initiative as reported by the health endpoint.
+type BreakerStatus struct {
+	State               BreakerState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+}
+
+// Statuses returns the status of every breaker by initiative ID.
+func (b *Breakers) Statuses() map[string]BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	statuses := make(map[string]BreakerStatus, len(b.breakers))
+
+	for rn, breaker := range b.breakers {
+		state, failures := breaker.State()
+		statuses[rn.String()] = BreakerStatus{State: state, ConsecutiveFailures: failures}
+	}
+
+	return statuses
+}
+
+func (b *Breakers) observe(registrationNumber RegistrationNumber, breaker *CircuitBreaker) {
+	state, _ := breaker.State()
+	b.State.WithLabelValues(registrationNumber.String()).Set(float64(state))
+}
@@ -0,0 +1,209 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main_test
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	eci "github.com/tvanriel/eci-prometheus-exporter"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	start := time.Now()
+
+	tests := map[string]struct {
+		run  func(b *eci.CircuitBreaker) error
+		want eci.BreakerState
+		err  assert.ErrorAssertionFunc
+	}{
+		"stays closed below the threshold": {
+			run: func(b *eci.CircuitBreaker) error {
+				b.Failure(start)
+				b.Failure(start)
+
+				return b.Allow(start)
+			},
+			want: eci.BreakerClosed,
+			err:  assert.NoError,
+		},
+		"opens at the threshold": {
+			run: func(b *eci.CircuitBreaker) error {
+				for range 3 {
+					b.Failure(start)
+				}
+
+				return b.Allow(start.Add(time.Second))
+			},
+			want: eci.BreakerOpen,
+			err:  errIs(eci.ErrCircuitOpen),
+		},
+		"success resets the failures": {
+			run: func(b *eci.CircuitBreaker) error {
+				b.Failure(start)
+				b.Failure(start)
+				b.Success()
+				b.Failure(start)
+				b.Failure(start)
+
+				return b.Allow(start)
+			},
+			want: eci.BreakerClosed,
+			err:  assert.NoError,
+		},
+		"half-open after the cooldown allows one probe": {
+			run: func(b *eci.CircuitBreaker) error {
+				for range 3 {
+					b.Failure(start)
+				}
+
+				err := b.Allow(start.Add(time.Minute))
+				if err != nil {
+					return err
+				}
+
+				return b.Allow(start.Add(time.Minute))
+			},
+			want: eci.BreakerHalfOpen,
+			err:  errIs(eci.ErrCircuitOpen),
+		},
+		"failed probe opens again": {
+			run: func(b *eci.CircuitBreaker) error {
+				for range 3 {
+					b.Failure(start)
+				}
+
+				_ = b.Allow(start.Add(time.Minute))
+				b.Failure(start.Add(time.Minute))
+
+				return b.Allow(start.Add(time.Minute + time.Second))
+			},
+			want: eci.BreakerOpen,
+			err:  errIs(eci.ErrCircuitOpen),
+		},
+		"successful probe closes": {
+			run: func(b *eci.CircuitBreaker) error {
+				for range 3 {
+					b.Failure(start)
+				}
+
+				_ = b.Allow(start.Add(time.Minute))
+				b.Success()
+
+				return b.Allow(start.Add(time.Minute))
+			},
+			want: eci.BreakerClosed,
+			err:  assert.NoError,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			b := &eci.CircuitBreaker{Threshold: 3, Cooldown: time.Minute}
+			tt.err(t, tt.run(b))
+
+			state, _ := b.State()
+			assert.Equal(t, tt.want, state)
+		})
+	}
+}
+
+func TestApplication_FetchAndUpdateMetricsCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	rn := *MustParseRegistrationNumber("ECI(2024)000007")
+	app := eci.NewApplication(zaptest.NewLogger(t), server.URL, []eci.RegistrationNumber{rn}, "", http.DefaultClient)
+	app.Breakers = eci.NewBreakers(2, time.Hour, app.Initiatives)
+
+	require.ErrorIs(t, app.FetchAndUpdateMetrics(t.Context(), rn), eci.ErrNon200)
+	require.ErrorIs(t, app.FetchAndUpdateMetrics(t.Context(), rn), eci.ErrNon200)
+	require.ErrorIs(t, app.FetchAndUpdateMetrics(t.Context(), rn), eci.ErrCircuitOpen)
+
+	assert.Equal(t, int32(2), calls.Load(), "no request while the breaker is open")
+	assert.InDelta(t, float64(eci.BreakerOpen), testutil.ToFloat64(app.Breakers.State.WithLabelValues(rn.String())), 0)
+
+	rec := httptest.NewRecorder()
+	app.HTTPServer.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var health eci.HealthStatus
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&health))
+	assert.Equal(t, eci.HealthDegraded, health.Status)
+	assert.Equal(t, eci.BreakerStatus{State: eci.BreakerOpen, ConsecutiveFailures: 2}, health.Initiatives[rn.String()])
+}
+
+func TestApplication_FetchTracksOnlyPolledInitiatives(t *testing.T) {
+	t.Parallel()
+
+	server := ServerWantsCallForInitiativeID(MustParseRegistrationNumber("ECI(2025)000001"))(t)
+	t.Cleanup(server.Close)
+
+	rn := *MustParseRegistrationNumber("ECI(2024)000007")
+	app := eci.NewApplication(zaptest.NewLogger(t), server.URL, []eci.RegistrationNumber{rn}, "", http.DefaultClient)
+
+	rec := httptest.NewRecorder()
+	app.HTTPServer.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/probe?target=ECI(2025)000001", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), `eci_probe_success{initiative_id="ECI(2025)000001"} 1`)
+
+	assert.Equal(t, []string{rn.String()}, slices.Collect(maps.Keys(app.Breakers.Statuses())))
+	assert.Equal(t, 1, testutil.CollectAndCount(app.Breakers.State))
+	assert.Equal(t, 0, testutil.CollectAndCount(app.APIDurationVec))
+}
+
+func TestBreakers_Disabled(t *testing.T) {
+	t.Parallel()
+
+	rn := *MustParseRegistrationNumber("ECI(2024)000007")
+	b := eci.NewBreakers(0, time.Minute, nil)
+
+	b.Success(rn)
+	b.Failure(rn)
+	require.NoError(t, b.Allow(rn))
+
+	assert.Empty(t, b.Statuses())
+	assert.Equal(t, 0, testutil.CollectAndCount(b.State))
+}
+
+func TestApplication_FetchNotModifiedWithoutCache(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	t.Cleanup(server.Close)
+
+	rn := *MustParseRegistrationNumber("ECI(2024)000007")
+	app := eci.NewApplication(zaptest.NewLogger(t), server.URL, []eci.RegistrationNumber{rn}, "", http.DefaultClient)
+	app.Breakers = eci.NewBreakers(5, time.Hour, app.Initiatives)
+
+	for range 3 {
+		require.ErrorIs(t, app.FetchAndUpdateMetrics(t.Context(), rn), eci.ErrNon200)
+	}
+
+	assert.Equal(t, eci.BreakerStatus{State: eci.BreakerClosed, ConsecutiveFailures: 3}, app.Breakers.Statuses()[rn.String()],
+		"a 304 without a cached report is one failure")
+}
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"net/http"
+	"sync"
+)
+
+// Reasons for a cache hit.
+const (
+	CacheHitETag         = "etag"
+	CacheHitLastModified = "last_modified"
+	CacheHitBodyHash     = "body_hash"
+)
+
+// ResponseCache remembers the last report of every initiative with the validators of the response, so unchanged
+// reports can be detected without downloading or decoding them again.
+type ResponseCache struct {
+	mu      sync.Mutex
+	entries map[RegistrationNumber]cachedResponse
+}
+
+type cachedResponse struct {
+	etag         string
+	lastModified string
+	bodyHash     [sha256.Size]byte
+	report       *ProgressResponse
+}
+
+// NewResponseCache creates an empty [ResponseCache].
+func NewResponseCache() *ResponseCache {
+	return &ResponseCache{entries: map[RegistrationNumber]cachedResponse{}}
+}
+
+// SetConditionalHeaders adds If-None-Match and If-Modified-Since when the previous response had validators.
+func (c *ResponseCache) SetConditionalHeaders(registrationNumber RegistrationNumber, req *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[registrationNumber]
+	if !ok {
+		return
+	}
+
+	if entry.etag != "" {
+		req.Header.Set("If-None-Match", entry.etag)
+	}
+
+	if entry.lastModified != "" {
+		req.Header.Set("If-Modified-Since", entry.lastModified)
+	}
+}
+
+// NotModified returns the cached report after a 304 response, together with the validator that matched.
+func (c *ResponseCache) NotModified(registrationNumber RegistrationNumber) (*ProgressResponse, string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[registrationNumber]
+	if !ok {
+		return nil, "", false
+	}
+
+	if entry.etag != "" {
+		return entry.report, CacheHitETag, true
+	}
+
+	return entry.report, CacheHitLastModified, true
+}
+
+// Lookup returns the cached report when the body is the same as the body of the previous response.
+func (c *ResponseCache) Lookup(registrationNumber RegistrationNumber, body []byte) (*ProgressResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[registrationNumber]
+	if !ok || !bytes.Equal(entry.bodyHash[:], hashBody(body)) {
+		return nil, false
+	}
+
+	return entry.report, true
+}
+
+// Store remembers the report and the validators of the response it was decoded from.
+func (c *ResponseCache) Store(registrationNumber RegistrationNumber, resp *http.Response, body []byte, report *ProgressResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := cachedResponse{
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+		report:       report,
+	}
+	copy(entry.bodyHash[:], hashBody(body))
+
+	c.entries[registrationNumber] = entry
+}
+
+// Delete forgets the initiative.
+func (c *ResponseCache) Delete(registrationNumber RegistrationNumber) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, registrationNumber)
+}
+
+func hashBody(body []byte) []byte {
+	sum := sha256.Sum256(body)
+
+	return sum[:]
+}
@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	eci "github.com/tvanriel/eci-prometheus-exporter"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestApplication_FetchAndUpdateMetricsConditional(t *testing.T) {
+	t.Parallel()
+
+	const lastModified = "Wed, 04 Jun 2025 10:00:00 GMT"
+
+	tests := map[string]struct {
+		handler      func(w http.ResponseWriter, r *http.Request)
+		wantHits     map[string]float64
+		wantMisses   float64
+		wantDownload int32
+	}{
+		"etag": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("If-None-Match") == `"v1"` {
+					w.WriteHeader(http.StatusNotModified)
+
+					return
+				}
+
+				w.Header().Set("ETag", `"v1"`)
+				_, _ = w.Write([]byte(defaultResponse))
+			},
+			wantHits:     map[string]float64{eci.CacheHitETag: 1},
+			wantMisses:   1,
+			wantDownload: 1,
+		},
+		"last modified": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("If-Modified-Since") == lastModified {
+					w.WriteHeader(http.StatusNotModified)
+
+					return
+				}
+
+				w.Header().Set("Last-Modified", lastModified)
+				_, _ = w.Write([]byte(defaultResponse))
+			},
+			wantHits:     map[string]float64{eci.CacheHitLastModified: 1},
+			wantMisses:   1,
+			wantDownload: 1,
+		},
+		"body hash": {
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(defaultResponse))
+			},
+			wantHits:     map[string]float64{eci.CacheHitBodyHash: 1},
+			wantMisses:   1,
+			wantDownload: 2,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var downloads atomic.Int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				rec := httptest.NewRecorder()
+				tt.handler(rec, r)
+
+				if rec.Code == http.StatusOK {
+					downloads.Add(1)
+				}
+
+				for k, v := range rec.Header() {
+					w.Header()[k] = v
+				}
+
+				w.WriteHeader(rec.Code)
+				_, _ = w.Write(rec.Body.Bytes())
+			}))
+			defer server.Close()
+
+			rn := *MustParseRegistrationNumber("ECI(2024)000007")
+			app := eci.NewApplication(zaptest.NewLogger(t), server.URL, []eci.RegistrationNumber{rn}, "", http.DefaultClient)
+
+			require.NoError(t, app.FetchAndUpdateMetrics(t.Context(), rn))
+			require.NoError(t, app.FetchAndUpdateMetrics(t.Context(), rn))
+
+			assert.Equal(t, tt.wantDownload, downloads.Load())
+			assert.InDelta(t, tt.wantMisses, testutil.ToFloat64(app.CacheMisses.WithLabelValues(rn.String())), 0)
+
+			for validator, want := range tt.wantHits {
+				assert.InDelta(t, want, testutil.ToFloat64(app.CacheHits.WithLabelValues(rn.String(), validator)), 0)
+			}
+
+			report, err := app.Fetch(t.Context(), rn)
+			require.NoError(t, err)
+			assert.Equal(t, 1_149_248, report.SOSReport.TotalSignatures)
+			assert.Equal(t, tt.wantDownload+1, downloads.Load(), "Fetch does not use the cache")
+		})
+	}
+}
+
+func TestApplication_FetchAndUpdateMetricsChangedBody(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) == 1 {
+			_, _ = w.Write([]byte(defaultResponse))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(strings.Replace(defaultResponse, `"NL","total":75811`, `"NL","total":76000`, 1)))
+	}))
+	defer server.Close()
+
+	rn := *MustParseRegistrationNumber("ECI(2024)000007")
+	app := eci.NewApplication(zaptest.NewLogger(t), server.URL, []eci.RegistrationNumber{rn}, "", http.DefaultClient)
+
+	require.NoError(t, app.FetchAndUpdateMetrics(t.Context(), rn))
+	require.NoError(t, app.FetchAndUpdateMetrics(t.Context(), rn))
+
+	assert.InDelta(t, 2, testutil.ToFloat64(app.CacheMisses.WithLabelValues(rn.String())), 0)
+	assert.Equal(t, 76000, app.Reports.Snapshot()[rn.String()][19].Signatures)
+}
@@ -0,0 +1,209 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CountryProgress is the number of signatures and the threshold of a member state.
+type CountryProgress struct {
+	CountryCode string
+	Signatures  int
+	Threshold   int
+
+	// Population is the number of inhabitants of the member state, 0 when unknown.
+	Population int
+	// Total is the total number of signatures of the initiative.
+	Total int
+}
+
+// perInhabitants is the number of inhabitants [CountryProgress.PerCapita] is relative to.
+const perInhabitants = 100_000
+
+// PerCapita returns the signatures per 100,000 inhabitants. It is false when the population is unknown.
+func (p CountryProgress) PerCapita() (float64, bool) {
+	return ratio(p.Signatures*perInhabitants, p.Population)
+}
+
+// Share returns the share of the total signatures of the initiative that were collected in the member state.
+func (p CountryProgress) Share() (float64, bool) {
+	return ratio(p.Signatures, p.Total)
+}
+
+// Progress returns the signatures relative to the threshold of the member state.
+func (p CountryProgress) Progress() (float64, bool) {
+	return ratio(p.Signatures, p.Threshold)
+}
+
+func ratio(n, d int) (float64, bool) {
+	if d <= 0 {
+		return 0, false
+	}
+
+	return float64(n) / float64(d), true
+}
+
+// ReportSnapshot holds the progress of every initiative by initiative ID. A snapshot is never modified after it
+// has been published.
+type ReportSnapshot map[string][]CountryProgress
+
+// ReportCollector is a [prometheus.Collector] for the signature and threshold metrics. It exposes an atomically
+// replaced snapshot of the latest reports, so a scrape never mixes values of different polls and initiatives that
+// are deleted disappear from the next scrape.
+type ReportCollector struct {
+	signatures *prometheus.Desc
+	thresholds *prometheus.Desc
+	perCapita  *prometheus.Desc
+	share      *prometheus.Desc
+	progress   *prometheus.Desc
+	population *prometheus.Desc
+
+	// mu serialises the writers, readers only load the snapshot.
+	mu       sync.Mutex
+	snapshot atomic.Pointer[ReportSnapshot]
+}
+
+// NewReportCollector creates an empty [ReportCollector].
+func NewReportCollector() *ReportCollector {
+	c := &ReportCollector{
+		signatures: prometheus.NewDesc(
+			MetricSignatures,
+			"Number of signatures collected by the European Citizens Initiative Per Country",
+			[]string{"initiative_id", "country_code"}, nil,
+		),
+		thresholds: prometheus.NewDesc(
+			MetricSignatureThreshold,
+			"Threshold number of signatures for the European Citizens Initiative",
+			[]string{"initiative_id", "country_code"}, nil,
+		),
+		perCapita: prometheus.NewDesc(
+			MetricSignaturesPerCapita,
+			"Number of signatures per 100,000 inhabitants of the member state",
+			[]string{"initiative_id", "country_code"}, nil,
+		),
+		share: prometheus.NewDesc(
+			MetricSignatureShare,
+			"Share of the total signatures of the initiative collected in the member state",
+			[]string{"initiative_id", "country_code"}, nil,
+		),
+		progress: prometheus.NewDesc(
+			MetricSignatureProgress,
+			"Number of signatures relative to the threshold of the member state",
+			[]string{"initiative_id", "country_code"}, nil,
+		),
+		population: prometheus.NewDesc(
+			MetricPopulation,
+			"Number of inhabitants of the member state used for the per-capita metrics, by year of the source",
+			[]string{"country_code", "year"}, nil,
+		),
+	}
+
+	c.snapshot.Store(&ReportSnapshot{})
+
+	return c
+}
+
+// Snapshot returns the current snapshot. It must not be modified.
+func (c *ReportCollector) Snapshot() ReportSnapshot {
+	return *c.snapshot.Load()
+}
+
+// Set replaces the progress of the initiative with the countries of the report. The last entry of a duplicated
+// country wins.
+func (c *ReportCollector) Set(registrationNumber RegistrationNumber, report *ProgressResponse, th Threshold) {
+	progress := make([]CountryProgress, 0, len(report.SOSReport.Entries))
+	index := make(map[string]int, len(report.SOSReport.Entries))
+
+	for _, e := range report.SOSReport.Entries {
+		code := Countries.MemberCountryCode(e.CountryCode)
+		p := CountryProgress{
+			CountryCode: e.CountryCode,
+			Signatures:  e.Total,
+			Threshold:   th[code],
+			Population:  Populations[code].Inhabitants,
+			Total:       report.SOSReport.TotalSignatures,
+		}
+
+		if i, ok := index[e.CountryCode]; ok {
+			progress[i] = p
+
+			continue
+		}
+
+		index[e.CountryCode] = len(progress)
+		progress = append(progress, p)
+	}
+
+	c.update(func(s ReportSnapshot) { s[registrationNumber.String()] = progress })
+}
+
+// Delete removes the initiative from the collector.
+func (c *ReportCollector) Delete(registrationNumber RegistrationNumber) {
+	c.update(func(s ReportSnapshot) { delete(s, registrationNumber.String()) })
+}
+
+// update publishes a modified copy of the current snapshot.
+func (c *ReportCollector) update(modify func(ReportSnapshot)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	next := maps.Clone(*c.snapshot.Load())
+	modify(next)
+	c.snapshot.Store(&next)
+}
+
+// Describe implements [prometheus.Collector].
+func (c *ReportCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.signatures
+	ch <- c.thresholds
+	ch <- c.perCapita
+	ch <- c.share
+	ch <- c.progress
+	ch <- c.population
+}
+
+// Collect implements [prometheus.Collector].
+func (c *ReportCollector) Collect(ch chan<- prometheus.Metric) {
+	snapshot := c.Snapshot()
+
+	ids := make([]string, 0, len(snapshot))
+	for id := range snapshot {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		for _, p := range snapshot[id] {
+			ch <- prometheus.MustNewConstMetric(c.signatures, prometheus.GaugeValue, float64(p.Signatures), id, p.CountryCode)
+			ch <- prometheus.MustNewConstMetric(c.thresholds, prometheus.GaugeValue, float64(p.Threshold), id, p.CountryCode)
+
+			for _, derived := range []struct {
+				desc  *prometheus.Desc
+				value func() (float64, bool)
+			}{
+				{c.perCapita, p.PerCapita},
+				{c.share, p.Share},
+				{c.progress, p.Progress},
+			} {
+				if v, ok := derived.value(); ok {
+					ch <- prometheus.MustNewConstMetric(derived.desc, prometheus.GaugeValue, v, id, p.CountryCode)
+				}
+			}
+		}
+	}
+
+	for _, code := range slices.Sorted(maps.Keys(Populations)) {
+		population := Populations[code]
+		ch <- prometheus.MustNewConstMetric(c.population, prometheus.GaugeValue, float64(population.Inhabitants),
+			Countries.Normalize(string(code)), strconv.Itoa(population.Year))
+	}
+}
@@ -0,0 +1,159 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	eci "github.com/tvanriel/eci-prometheus-exporter"
+)
+
+func TestReportCollector(t *testing.T) {
+	t.Parallel()
+
+	th := eci.GetThresholds(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	first := *MustParseRegistrationNumber("ECI(2024)000007")
+	second := *MustParseRegistrationNumber("ECI(2024)000008")
+
+	report := func(entries ...eci.SOSEntry) *eci.ProgressResponse {
+		return &eci.ProgressResponse{SOSReport: eci.SOSReport{Entries: entries}}
+	}
+
+	tests := map[string]struct {
+		update func(c *eci.ReportCollector)
+		want   string
+	}{
+		"replaces countries": {
+			update: func(c *eci.ReportCollector) {
+				c.Set(first, report(eci.SOSEntry{CountryCode: "NL", Total: 10}, eci.SOSEntry{CountryCode: "DE", Total: 20}), th)
+				c.Set(first, report(eci.SOSEntry{CountryCode: "NL", Total: 11}), th)
+			},
+			want: `
+eci_signatures{country_code="NL",initiative_id="ECI(2024)000007"} 11
+`,
+		},
+		"deletes initiatives": {
+			update: func(c *eci.ReportCollector) {
+				c.Set(first, report(eci.SOSEntry{CountryCode: "NL", Total: 10}), th)
+				c.Set(second, report(eci.SOSEntry{CountryCode: "BE", Total: 5}), th)
+				c.Delete(first)
+			},
+			want: `
+eci_signatures{country_code="BE",initiative_id="ECI(2024)000008"} 5
+`,
+		},
+		"duplicated country": {
+			update: func(c *eci.ReportCollector) {
+				c.Set(first, report(eci.SOSEntry{CountryCode: "NL", Total: 10}, eci.SOSEntry{CountryCode: "NL", Total: 12}), th)
+			},
+			want: `
+eci_signatures{country_code="NL",initiative_id="ECI(2024)000007"} 12
+`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			c := eci.NewReportCollector()
+			tt.update(c)
+
+			want := "# HELP eci_signatures Number of signatures collected by the European Citizens Initiative Per Country\n" +
+				"# TYPE eci_signatures gauge" + tt.want
+
+			require.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(want), eci.MetricSignatures))
+		})
+	}
+}
+
+func TestReportCollector_DerivedMetrics(t *testing.T) {
+	t.Parallel()
+
+	th := eci.GetThresholds(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	rn := *MustParseRegistrationNumber("ECI(2024)000007")
+	c := eci.NewReportCollector()
+
+	c.Set(rn, &eci.ProgressResponse{SOSReport: eci.SOSReport{
+		TotalSignatures: 100_000,
+		Entries: []eci.SOSEntry{
+			{CountryCode: "NL", Total: 44_640},
+			{CountryCode: "XX", Total: 55_360},
+		},
+	}}, th)
+
+	progress := c.Snapshot()[rn.String()]
+	require.Len(t, progress, 2)
+
+	perCapita, ok := progress[0].PerCapita()
+	assert.True(t, ok)
+	assert.InDelta(t, 44_640*100_000/17_942_942.0, perCapita, 1e-9)
+
+	_, ok = progress[1].PerCapita()
+	assert.False(t, ok, "unknown country has no population")
+
+	want := `
+# HELP eci_signature_progress_ratio Number of signatures relative to the threshold of the member state
+# TYPE eci_signature_progress_ratio gauge
+eci_signature_progress_ratio{country_code="NL",initiative_id="ECI(2024)000007"} 2
+# HELP eci_signature_share_ratio Share of the total signatures of the initiative collected in the member state
+# TYPE eci_signature_share_ratio gauge
+eci_signature_share_ratio{country_code="NL",initiative_id="ECI(2024)000007"} 0.4464
+eci_signature_share_ratio{country_code="XX",initiative_id="ECI(2024)000007"} 0.5536
+`
+
+	require.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(want),
+		eci.MetricSignatureProgress, eci.MetricSignatureShare))
+	assert.Equal(t, 1, testutil.CollectAndCount(c, eci.MetricSignaturesPerCapita))
+	assert.Equal(t, len(eci.Populations), testutil.CollectAndCount(c, eci.MetricPopulation))
+}
+
+func TestReportCollector_ConsistentScrapes(t *testing.T) {
+	t.Parallel()
+
+	th := eci.GetThresholds(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	rn := *MustParseRegistrationNumber("ECI(2024)000007")
+	c := eci.NewReportCollector()
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(c)
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		for i := range 200 {
+			c.Set(rn, &eci.ProgressResponse{SOSReport: eci.SOSReport{Entries: []eci.SOSEntry{
+				{CountryCode: "NL", Total: i},
+				{CountryCode: "DE", Total: i},
+			}}}, th)
+		}
+	}()
+
+	for range 200 {
+		families, err := registry.Gather()
+		require.NoError(t, err)
+
+		for _, f := range families {
+			if f.GetName() != eci.MetricSignatures {
+				continue
+			}
+
+			// Both countries are always updated together, so a scrape must never see different values.
+			metrics := f.GetMetric()
+			require.Len(t, metrics, 2)
+			assert.InDelta(t, metrics[0].GetGauge().GetValue(), metrics[1].GetGauge().GetValue(), 0)
+		}
+	}
+
+	wg.Wait()
+}
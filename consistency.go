@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// Issues with the country entries of a report.
+const (
+	IssueDuplicate = "duplicate"
+	IssueUnknown   = "unknown"
+)
+
+// ReportValidation is the result of checking a report for consistency.
+type ReportValidation struct {
+	// Discrepancy is TotalSignatures minus the sum of the country entries.
+	Discrepancy int
+	// Duplicates are the country codes that occur more than once.
+	Duplicates []string
+	// Unknown are the country codes without a threshold.
+	Unknown []string
+}
+
+// Consistent reports whether no problems were found.
+func (v ReportValidation) Consistent() bool {
+	return v.Discrepancy == 0 && len(v.Duplicates) == 0 && len(v.Unknown) == 0
+}
+
+// ValidateReport checks that the country entries add up to the total and that every country occurs once
+// and has a threshold.
+func ValidateReport(report *ProgressResponse, thresholds Threshold) ReportValidation {
+	var (
+		v     ReportValidation
+		sum   int
+		count = make(map[string]int, len(report.SOSReport.Entries))
+	)
+
+	for _, e := range report.SOSReport.Entries {
+		sum += e.Total
+		count[e.CountryCode]++
+
+		if count[e.CountryCode] == 2 { //nolint:mnd // report a duplicate once.
+			v.Duplicates = append(v.Duplicates, e.CountryCode)
+		}
+
+		if count[e.CountryCode] > 1 {
+			continue
+		}
+
+		if _, ok := thresholds[Countries.MemberCountryCode(e.CountryCode)]; !ok {
+			v.Unknown = append(v.Unknown, e.CountryCode)
+		}
+	}
+
+	v.Discrepancy = report.SOSReport.TotalSignatures - sum
+
+	return v
+}
+
+// checkConsistency validates the report and exposes the result in the metrics.
+func (a *Application) checkConsistency(
+	registrationNumber RegistrationNumber,
+	report *ProgressResponse,
+	thresholds Threshold,
+	logger *zap.Logger,
+) {
+	v := ValidateReport(report, thresholds)
+	initiativeID := registrationNumber.String()
+
+	a.ReportInconsistency.WithLabelValues(initiativeID).Set(float64(v.Discrepancy))
+	a.ReportCountryIssues.DeletePartialMatch(prometheus.Labels{"initiative_id": initiativeID})
+
+	for _, c := range v.Duplicates {
+		a.ReportCountryIssues.WithLabelValues(initiativeID, c, IssueDuplicate).Set(1)
+	}
+
+	for _, c := range v.Unknown {
+		a.ReportCountryIssues.WithLabelValues(initiativeID, c, IssueUnknown).Set(1)
+	}
+
+	if v.Consistent() {
+		return
+	}
+
+	logger.Warn("Inconsistent report",
+		zap.Int("total_signatures", report.SOSReport.TotalSignatures),
+		zap.Int("discrepancy", v.Discrepancy),
+		zap.Strings("duplicate_countries", v.Duplicates),
+		zap.Strings("unknown_countries", v.Unknown),
+	)
+}
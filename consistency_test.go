@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	eci "github.com/tvanriel/eci-prometheus-exporter"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestValidateReport(t *testing.T) {
+	t.Parallel()
+
+	thresholds := eci.GetThresholds(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	tests := map[string]struct {
+		report *eci.ProgressResponse
+		want   eci.ReportValidation
+	}{
+		"consistent": {
+			report: &eci.ProgressResponse{SOSReport: eci.SOSReport{
+				TotalSignatures: 30,
+				Entries:         []eci.SOSEntry{{CountryCode: "NL", Total: 10}, {CountryCode: "DE", Total: 20}},
+			}},
+			want: eci.ReportValidation{},
+		},
+		"total exceeds entries": {
+			report: &eci.ProgressResponse{SOSReport: eci.SOSReport{
+				TotalSignatures: 35,
+				Entries:         []eci.SOSEntry{{CountryCode: "NL", Total: 10}, {CountryCode: "DE", Total: 20}},
+			}},
+			want: eci.ReportValidation{Discrepancy: 5},
+		},
+		"duplicated and unknown countries": {
+			report: &eci.ProgressResponse{SOSReport: eci.SOSReport{
+				TotalSignatures: 30,
+				Entries: []eci.SOSEntry{
+					{CountryCode: "NL", Total: 10},
+					{CountryCode: "NL", Total: 10},
+					{CountryCode: "NL", Total: 5},
+					{CountryCode: "XX", Total: 5},
+				},
+			}},
+			want: eci.ReportValidation{Duplicates: []string{"NL"}, Unknown: []string{"XX"}},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := eci.ValidateReport(tt.report, thresholds)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.want.Discrepancy == 0 && tt.want.Duplicates == nil && tt.want.Unknown == nil, got.Consistent())
+		})
+	}
+}
+
+//nolint:lll // testdata
+const inconsistentResponse = `{"sosReport":{"totalSignatures":100,"entry":[{"countryCodeType":"NL","total":40},{"countryCodeType":"NL","total":40},{"countryCodeType":"XX","total":10}]},"registrationDate":"19/06/2024"}`
+
+func TestApplication_FetchAndUpdateMetricsConsistency(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(inconsistentResponse))
+	}))
+	defer server.Close()
+
+	rn := *MustParseRegistrationNumber("ECI(2024)000007")
+	app := eci.NewApplication(zaptest.NewLogger(t), server.URL, []eci.RegistrationNumber{rn}, "", http.DefaultClient)
+
+	require.NoError(t, app.FetchAndUpdateMetrics(t.Context(), rn))
+
+	assert.InDelta(t, 10, testutil.ToFloat64(app.ReportInconsistency.WithLabelValues(rn.String())), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(app.ReportCountryIssues.WithLabelValues(rn.String(), "NL", eci.IssueDuplicate)), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(app.ReportCountryIssues.WithLabelValues(rn.String(), "XX", eci.IssueUnknown)), 0)
+}
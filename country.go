@@ -0,0 +1,182 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Languages are the official languages of the EU, in which every country has a name.
+var Languages = []string{
+	"bg", "cs", "da", "de", "el", "en", "es", "et", "fi", "fr", "ga", "hr",
+	"hu", "it", "lt", "lv", "mt", "nl", "pl", "pt", "ro", "sk", "sl", "sv",
+}
+
+// defaultLanguage is the language of [Country.Name] when there is no name in the requested language.
+const defaultLanguage = "en"
+
+// ErrInvalidCountries is returned when the country dataset cannot be parsed.
+var ErrInvalidCountries = errors.New("invalid country data")
+
+// Country is a member state, or former member state, that can appear in ECI reports.
+type Country struct {
+	Code   string            // ISO 3166-1 alpha-2, e.g. "GR"
+	EUCode string            // code used by the EU institutions, e.g. "EL"
+	Names  map[string]string // by language, e.g. "de": "Griechenland"
+}
+
+// Name returns the name of the country in the language, or in English when there is no such name.
+func (c Country) Name(language string) string {
+	if name, ok := c.Names[language]; ok {
+		return name
+	}
+
+	return c.Names[defaultLanguage]
+}
+
+// MemberCountryCode returns the key of the country in a [Threshold].
+func (c Country) MemberCountryCode() MemberCountryCode {
+	return MemberCountryCode(strings.ToLower(c.Code))
+}
+
+// CountryRegistry looks up countries by their ISO 3166 or EU code, in any case.
+type CountryRegistry struct {
+	countries []Country
+	byCode    map[string]int
+}
+
+// NewCountryRegistry creates a registry of the countries.
+func NewCountryRegistry(countries []Country) *CountryRegistry {
+	r := &CountryRegistry{countries: countries, byCode: make(map[string]int, 2*len(countries))}
+
+	for i, c := range countries {
+		r.byCode[c.Code] = i
+		r.byCode[c.EUCode] = i
+	}
+
+	return r
+}
+
+//go:embed data/countries.csv
+var countriesCSV []byte
+
+// Countries is the registry of the EU member states and the United Kingdom.
+var Countries = NewCountryRegistry(mustParseCountries(countriesCSV))
+
+// Lookup finds the country by its ISO 3166 or EU code, e.g. "GR", "el" or "UK".
+func (r *CountryRegistry) Lookup(code string) (Country, bool) {
+	i, ok := r.byCode[strings.ToUpper(strings.TrimSpace(code))]
+	if !ok {
+		return Country{}, false
+	}
+
+	return r.countries[i], true
+}
+
+// Normalize returns the ISO 3166 code of the country, which is used in the country_code labels. Unknown codes are
+// returned in upper case.
+func (r *CountryRegistry) Normalize(code string) string {
+	if c, ok := r.Lookup(code); ok {
+		return c.Code
+	}
+
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// MemberCountryCode returns the key of the country in a [Threshold].
+func (r *CountryRegistry) MemberCountryCode(code string) MemberCountryCode {
+	return MemberCountryCode(strings.ToLower(r.Normalize(code)))
+}
+
+// All returns the countries of the registry.
+func (r *CountryRegistry) All() []Country {
+	return slices.Clone(r.countries)
+}
+
+// ParseCountries reads a CSV with a header of code, eu_code and a column with the names per language.
+func ParseCountries(r io.Reader) ([]Country, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCountries, err)
+	}
+
+	const codeColumns = 2
+	if len(records) == 0 || len(records[0]) < codeColumns {
+		return nil, fmt.Errorf("%w: missing header", ErrInvalidCountries)
+	}
+
+	languages := records[0][codeColumns:]
+	countries := make([]Country, 0, len(records)-1)
+
+	for _, record := range records[1:] {
+		c := Country{
+			Code:   strings.ToUpper(record[0]),
+			EUCode: strings.ToUpper(record[1]),
+			Names:  make(map[string]string, len(languages)),
+		}
+
+		for i, language := range languages {
+			if name := record[codeColumns+i]; name != "" {
+				c.Names[language] = name
+			}
+		}
+
+		countries = append(countries, c)
+	}
+
+	return countries, nil
+}
+
+func mustParseCountries(data []byte) []Country {
+	countries, err := ParseCountries(bytes.NewReader(data))
+	if err != nil {
+		panic(err)
+	}
+
+	return countries
+}
+
+// CountryInfoCollector is a [prometheus.Collector] that exposes the name of every country in every language, so
+// dashboards can show country names by joining on country_code.
+type CountryInfoCollector struct {
+	Registry *CountryRegistry
+
+	info *prometheus.Desc
+}
+
+// NewCountryInfoCollector creates a collector for the countries of the registry.
+func NewCountryInfoCollector(r *CountryRegistry) *CountryInfoCollector {
+	return &CountryInfoCollector{
+		Registry: r,
+		info: prometheus.NewDesc(
+			MetricCountryInfo,
+			"Name of the country per language, always 1",
+			[]string{"country_code", "eu_code", "language", "name"}, nil,
+		),
+	}
+}
+
+// Describe implements [prometheus.Collector].
+func (c *CountryInfoCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.info
+}
+
+// Collect implements [prometheus.Collector].
+func (c *CountryInfoCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, country := range c.Registry.countries {
+		for _, language := range slices.Sorted(maps.Keys(country.Names)) {
+			ch <- prometheus.MustNewConstMetric(c.info, prometheus.GaugeValue, 1,
+				country.Code, country.EUCode, language, country.Names[language])
+		}
+	}
+}
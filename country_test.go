@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	eci "github.com/tvanriel/eci-prometheus-exporter"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestCountryRegistry_Lookup(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		code       string
+		wantOK     bool
+		wantCode   string
+		wantMember eci.MemberCountryCode
+	}{
+		"ISO code":        {code: "DE", wantOK: true, wantCode: "DE", wantMember: "de"},
+		"lower case":      {code: "nl", wantOK: true, wantCode: "NL", wantMember: "nl"},
+		"whitespace":      {code: " be ", wantOK: true, wantCode: "BE", wantMember: "be"},
+		"Greece ISO code": {code: "GR", wantOK: true, wantCode: "GR", wantMember: "gr"},
+		"Greece EU code":  {code: "el", wantOK: true, wantCode: "GR", wantMember: "gr"},
+		"UK ISO code":     {code: "GB", wantOK: true, wantCode: "GB", wantMember: "gb"},
+		"UK EU code":      {code: "UK", wantOK: true, wantCode: "GB", wantMember: "gb"},
+		"unknown":         {code: "xx", wantOK: false, wantCode: "XX", wantMember: "xx"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			country, ok := eci.Countries.Lookup(tt.code)
+			assert.Equal(t, tt.wantOK, ok)
+
+			if ok {
+				assert.Equal(t, tt.wantCode, country.Code)
+				assert.Equal(t, tt.wantMember, country.MemberCountryCode())
+			}
+
+			assert.Equal(t, tt.wantCode, eci.Countries.Normalize(tt.code))
+			assert.Equal(t, tt.wantMember, eci.Countries.MemberCountryCode(tt.code))
+		})
+	}
+}
+
+func TestCountries(t *testing.T) {
+	t.Parallel()
+
+	countries := eci.Countries.All()
+	assert.Len(t, countries, 28)
+
+	for _, country := range countries {
+		for _, language := range eci.Languages {
+			assert.NotEmpty(t, country.Names[language], "%s in %s", country.Code, language)
+		}
+	}
+
+	for code := range eci.Populations {
+		_, ok := eci.Countries.Lookup(string(code))
+		assert.True(t, ok, code)
+	}
+
+	germany, ok := eci.Countries.Lookup("DE")
+	require.True(t, ok)
+	assert.Equal(t, "Deutschland", germany.Name("de"))
+	assert.Equal(t, "Germany", germany.Name("en"))
+	assert.Equal(t, "Germany", germany.Name("tlh"), "falls back to English")
+}
+
+func TestParseCountries(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		csv     string
+		want    []eci.Country
+		wantErr assert.ErrorAssertionFunc
+	}{
+		"valid": {
+			csv: "code,eu_code,de,en\ngr,el,Griechenland,\n",
+			want: []eci.Country{
+				{Code: "GR", EUCode: "EL", Names: map[string]string{"de": "Griechenland"}},
+			},
+			wantErr: assert.NoError,
+		},
+		"empty": {
+			csv:     "",
+			want:    nil,
+			wantErr: errContains("missing header"),
+		},
+		"missing column": {
+			csv:     "code,eu_code,de\ngr,el\n",
+			want:    nil,
+			wantErr: errContains("invalid country data"),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := eci.ParseCountries(strings.NewReader(tt.csv))
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCountryInfoCollector(t *testing.T) {
+	t.Parallel()
+
+	countries, err := eci.ParseCountries(strings.NewReader("code,eu_code,de,en\nGR,EL,Griechenland,Greece\n"))
+	require.NoError(t, err)
+
+	c := eci.NewCountryInfoCollector(eci.NewCountryRegistry(countries))
+
+	want := `
+# HELP eci_country_info Name of the country per language, always 1
+# TYPE eci_country_info gauge
+eci_country_info{country_code="GR",eu_code="EL",language="de",name="Griechenland"} 1
+eci_country_info{country_code="GR",eu_code="EL",language="en",name="Greece"} 1
+`
+
+	require.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(want)))
+	assert.Equal(t, 28*len(eci.Languages), testutil.CollectAndCount(eci.NewCountryInfoCollector(eci.Countries)))
+}
+
+func TestApplication_FetchNormalizesCountryCodes(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"sosReport":{"totalSignatures":30,"entry":[` +
+			`{"countryCodeType":"EL","total":10},{"countryCodeType":"uk","total":20}]},"registrationDate":"19/06/2019"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	rn := *MustParseRegistrationNumber("ECI(2019)000007")
+	app := eci.NewApplication(zaptest.NewLogger(t), server.URL, []eci.RegistrationNumber{rn}, "", http.DefaultClient)
+
+	require.NoError(t, app.FetchAndUpdateMetrics(t.Context(), rn))
+
+	assert.Equal(t, []eci.CountryProgress{
+		{CountryCode: "GR", Signatures: 10, Threshold: 15750, Population: 10397193, Total: 30},
+		{CountryCode: "GB", Signatures: 20, Threshold: 54750, Total: 30},
+	}, app.Reports.Snapshot()[rn.String()])
+}
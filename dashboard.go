@@ -0,0 +1,453 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Data source types and template variables of the generated dashboard.
+const (
+	DatasourcePrometheus = "prometheus"
+	DatasourceLoki       = "loki"
+
+	VariableInitiative = "initiative_id"
+	VariablePrometheus = "prometheus_datasource"
+	VariableLoki       = "loki_datasource"
+)
+
+// DashboardConfig parameterises the generated dashboard.
+type DashboardConfig struct {
+	Title string
+	UID   string
+	// LogSelector is the LogQL stream selector of the exporter logs.
+	LogSelector string
+}
+
+// Dashboard is the JSON model of a Grafana dashboard.
+type Dashboard struct {
+	UID           string         `json:"uid,omitempty"`
+	Title         string         `json:"title"`
+	Editable      bool           `json:"editable"`
+	SchemaVersion int            `json:"schemaVersion"`
+	Timezone      string         `json:"timezone"`
+	Time          DashboardRange `json:"time"`
+	Templating    Templating     `json:"templating"`
+	Panels        []Panel        `json:"panels"`
+}
+
+// DashboardRange is the default time range of a [Dashboard].
+type DashboardRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Templating holds the template variables of a [Dashboard].
+type Templating struct {
+	List []Variable `json:"list"`
+}
+
+// Variable is a dashboard template variable.
+type Variable struct {
+	Name       string           `json:"name"`
+	Label      string           `json:"label"`
+	Type       string           `json:"type"`
+	Query      string           `json:"query"`
+	Definition string           `json:"definition,omitempty"`
+	Datasource *DatasourceRef   `json:"datasource,omitempty"`
+	Refresh    int              `json:"refresh"`
+	Sort       int              `json:"sort,omitempty"`
+	Current    map[string]any   `json:"current"`
+	Options    []map[string]any `json:"options"`
+}
+
+// DatasourceRef references a data source, usually through a template variable.
+type DatasourceRef struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+// GridPos is the position of a [Panel] on the 24 column grid.
+type GridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Panel is a dashboard panel. FieldConfig and Options are passed to Grafana as is.
+type Panel struct {
+	ID          int            `json:"id"`
+	Type        string         `json:"type"`
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	Transparent bool           `json:"transparent,omitempty"`
+	GridPos     GridPos        `json:"gridPos"`
+	Datasource  *DatasourceRef `json:"datasource,omitempty"`
+	Targets     []Target       `json:"targets,omitempty"`
+	Links       []PanelLink    `json:"links,omitempty"`
+	FieldConfig FieldConfig    `json:"fieldConfig"`
+	Options     map[string]any `json:"options,omitempty"`
+}
+
+// PanelLink is a link in the header of a [Panel].
+type PanelLink struct {
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	TargetBlank bool   `json:"targetBlank"`
+}
+
+// FieldConfig is the field configuration of a [Panel].
+type FieldConfig struct {
+	Defaults  map[string]any `json:"defaults"`
+	Overrides []any          `json:"overrides"`
+}
+
+// Target is a query of a [Panel].
+type Target struct {
+	RefID        string         `json:"refId"`
+	Datasource   *DatasourceRef `json:"datasource,omitempty"`
+	Expr         string         `json:"expr"`
+	LegendFormat string         `json:"legendFormat,omitempty"`
+	Format       string         `json:"format,omitempty"`
+	Instant      bool           `json:"instant,omitempty"`
+	Range        bool           `json:"range,omitempty"`
+}
+
+// PrometheusExprs returns the PromQL expressions of all panels.
+func (d Dashboard) PrometheusExprs() []string {
+	var exprs []string
+
+	for _, p := range d.Panels {
+		for _, t := range p.Targets {
+			ds := t.Datasource
+			if ds == nil {
+				ds = p.Datasource
+			}
+
+			if ds == nil || ds.Type != DatasourcePrometheus {
+				continue
+			}
+
+			exprs = append(exprs, t.Expr)
+		}
+	}
+
+	return exprs
+}
+
+// thresholdSteps colours values red below and green from the given value.
+func thresholdSteps(green float64) map[string]any {
+	return map[string]any{
+		"mode": "absolute",
+		"steps": []map[string]any{
+			{"color": "red", "value": nil},
+			{"color": "green", "value": green},
+		},
+	}
+}
+
+func statOptions() map[string]any {
+	return map[string]any{
+		"colorMode":   "value",
+		"graphMode":   "area",
+		"justifyMode": "auto",
+		"orientation": "auto",
+		"textMode":    "auto",
+		"wideLayout":  true,
+		"reduceOptions": map[string]any{
+			"calcs":  []string{"lastNotNull"},
+			"fields": "",
+			"values": false,
+		},
+	}
+}
+
+func legendOptions() map[string]any {
+	return map[string]any{
+		"legend":  map[string]any{"displayMode": "list", "placement": "bottom", "showLegend": true},
+		"tooltip": map[string]any{"mode": "single", "sort": "none"},
+	}
+}
+
+// GenerateDashboard creates the dashboard of the exporter. The queries use the metric names of the exporter and
+// select the initiative through the initiative_id variable.
+//
+//nolint:funlen,maintidx // list of panels.
+func GenerateDashboard(cfg DashboardConfig) Dashboard {
+	prometheus := &DatasourceRef{Type: DatasourcePrometheus, UID: "${" + VariablePrometheus + "}"}
+	loki := &DatasourceRef{Type: DatasourceLoki, UID: "${" + VariableLoki + "}"}
+
+	sel := fmt.Sprintf(`{initiative_id="$%s"}`, VariableInitiative)
+	signatures := MetricSignatures + sel
+	threshold := MetricSignatureThreshold + sel
+	ratio := fmt.Sprintf("%s / on(initiative_id, country_code) %s", signatures, threshold)
+
+	target := func(expr, legend string) []Target {
+		return []Target{{RefID: "A", Expr: expr, LegendFormat: legend, Range: true}}
+	}
+
+	panels := []Panel{
+		{
+			Type:        "text",
+			Transparent: true,
+			GridPos:     GridPos{H: 4, W: 24, X: 0, Y: 0},
+			Links: []PanelLink{{
+				URL:         fmt.Sprintf("https://eci.ec.europa.eu/${%s:queryparam}/public/#/screen/home", VariableInitiative),
+				TargetBlank: true,
+			}},
+			FieldConfig: FieldConfig{Defaults: map[string]any{}},
+			Options: map[string]any{
+				"mode": "markdown",
+				"content": "# ![](https://citizens-initiative.europa.eu/themes/contrib/oe_theme/dist/eu/images/logo/" +
+					"standard-version/positive/logo-eu--en.svg)\n# European Citizens Initiative Tracker\n",
+			},
+		},
+		{
+			Type:        "stat",
+			Title:       "Countries where threshold was reached",
+			Description: "Shows the amount of countries where the signature threshold was reached",
+			GridPos:     GridPos{H: 4, W: 6, X: 0, Y: 4},
+			Targets:     target(fmt.Sprintf("count(%s >= 1)", ratio), "__auto"),
+			FieldConfig: FieldConfig{Defaults: map[string]any{
+				"color":      map[string]any{"mode": "thresholds"},
+				"thresholds": thresholdSteps(MinimumCountries),
+			}},
+			Options: statOptions(),
+		},
+		{
+			Type:        "timeseries",
+			Title:       "Signature percentage of threshold per country",
+			Description: "Shows the percentage of the threshold that was reached per country.",
+			GridPos:     GridPos{H: 13, W: 12, X: 6, Y: 4},
+			Targets:     target(ratio, "{{country_code}}"),
+			FieldConfig: FieldConfig{Defaults: map[string]any{
+				"color":      map[string]any{"mode": "palette-classic"},
+				"custom":     map[string]any{"thresholdsStyle": map[string]any{"mode": "area"}},
+				"thresholds": thresholdSteps(1),
+				"unit":       "percentunit",
+			}},
+			Options: legendOptions(),
+		},
+		{
+			Type:        "piechart",
+			Title:       "Signature contribution per country",
+			Description: "Shows the share of the signatures collected in every country.",
+			GridPos:     GridPos{H: 13, W: 6, X: 18, Y: 4},
+			Targets:     target(signatures, "{{country_code}}"),
+			FieldConfig: FieldConfig{Defaults: map[string]any{
+				"color": map[string]any{"mode": "palette-classic"},
+				"unit":  "none",
+			}},
+			Options: map[string]any{
+				"pieType":       "pie",
+				"displayLabels": []string{"name"},
+				"legend":        map[string]any{"displayMode": "list", "placement": "right", "showLegend": true},
+				"reduceOptions": map[string]any{"calcs": []string{"lastNotNull"}, "fields": "", "values": false},
+				"tooltip":       map[string]any{"mode": "single", "sort": "none"},
+			},
+		},
+		{
+			Type:        "stat",
+			Title:       "Countries under threshold",
+			Description: "Shows the amount of countries where the signature threshold was not reached yet",
+			GridPos:     GridPos{H: 4, W: 6, X: 0, Y: 8},
+			Targets:     target(fmt.Sprintf("count(%s < 1)", ratio), "__auto"),
+			FieldConfig: FieldConfig{Defaults: map[string]any{"color": map[string]any{"mode": "thresholds"}}},
+			Options:     statOptions(),
+		},
+		{
+			Type:    "stat",
+			Title:   "Closest country to threshold",
+			GridPos: GridPos{H: 5, W: 6, X: 0, Y: 12},
+			Targets: target(
+				fmt.Sprintf("topk(1, %s < 1)", ratio),
+				"{{country_code}}",
+			),
+			FieldConfig: FieldConfig{Defaults: map[string]any{
+				"color": map[string]any{"mode": "thresholds"},
+				"unit":  "percentunit",
+			}},
+			Options: statOptions(),
+		},
+		{
+			Type:    "timeseries",
+			Title:   "Signatures collected",
+			GridPos: GridPos{H: 10, W: 12, X: 0, Y: 17},
+			Targets: target(fmt.Sprintf("sum(%s) by(initiative_id)", signatures), "{{initiative_id}}"),
+			FieldConfig: FieldConfig{Defaults: map[string]any{
+				"color":      map[string]any{"mode": "palette-classic"},
+				"custom":     map[string]any{"thresholdsStyle": map[string]any{"mode": "line"}},
+				"thresholds": thresholdSteps(TotalSignatureGoal),
+			}},
+			Options: legendOptions(),
+		},
+		{
+			Type:    "timeseries",
+			Title:   "Signatures remaining",
+			GridPos: GridPos{H: 19, W: 12, X: 12, Y: 17},
+			Targets: target(
+				fmt.Sprintf("clamp_min(%s - on(initiative_id, country_code) %s, 0)", threshold, signatures),
+				"{{country_code}}",
+			),
+			FieldConfig: FieldConfig{Defaults: map[string]any{"color": map[string]any{"mode": "palette-classic"}}},
+			Options:     legendOptions(),
+		},
+		{
+			Type:    "timeseries",
+			Title:   "Total signatures remaining",
+			GridPos: GridPos{H: 9, W: 12, X: 0, Y: 27},
+			Targets: target(
+				fmt.Sprintf("clamp_min(%d - sum(%s) by(initiative_id), 0)", TotalSignatureGoal, signatures),
+				"{{initiative_id}}",
+			),
+			FieldConfig: FieldConfig{Defaults: map[string]any{"color": map[string]any{"mode": "palette-classic"}}},
+			Options:     legendOptions(),
+		},
+		{
+			Type:    "barchart",
+			Title:   "Signature rate in countries that don't reach the threshold",
+			GridPos: GridPos{H: 17, W: 12, X: 0, Y: 36},
+			Targets: target(
+				fmt.Sprintf("increase(%s[5m]) * on(initiative_id, country_code) group_left() ((%s) < 1)", signatures, ratio),
+				"{{country_code}}",
+			),
+			FieldConfig: FieldConfig{Defaults: map[string]any{
+				"color": map[string]any{"mode": "palette-classic"},
+				"unit":  "none",
+			}},
+			Options: map[string]any{"xTickLabelRotation": -45, "stacking": "normal"},
+		},
+		{
+			Type:    "barchart",
+			Title:   "ECI Initiative Signature Rate",
+			GridPos: GridPos{H: 17, W: 12, X: 12, Y: 36},
+			Targets: target(fmt.Sprintf("increase(%s[$__interval])", signatures), "{{country_code}}"),
+			FieldConfig: FieldConfig{Defaults: map[string]any{
+				"color": map[string]any{"mode": "palette-classic"},
+				"unit":  "none",
+			}},
+			Options: map[string]any{"xTickLabelRotation": -45, "stacking": "normal"},
+		},
+		{
+			Type:        "logs",
+			Title:       "Logs",
+			Description: "Log output of the exporter.",
+			GridPos:     GridPos{H: 8, W: 12, X: 0, Y: 53},
+			Datasource:  loki,
+			Targets: []Target{{
+				RefID:      "A",
+				Datasource: loki,
+				Expr: fmt.Sprintf(`%s | json | initiative_id = "$%s" or initiative_id = ""`,
+					cfg.LogSelector, VariableInitiative),
+			}},
+			FieldConfig: FieldConfig{Defaults: map[string]any{}},
+			Options:     map[string]any{"enableLogDetails": true, "sortOrder": "Descending"},
+		},
+		{
+			Type:    "heatmap",
+			Title:   "API call speed",
+			GridPos: GridPos{H: 8, W: 12, X: 12, Y: 53},
+			Targets: []Target{{
+				RefID:  "A",
+				Expr:   fmt.Sprintf("sum(increase(%s_bucket%s[1m])) by (le)", MetricAPIDuration, sel),
+				Format: "heatmap",
+				Range:  true,
+			}},
+			FieldConfig: FieldConfig{Defaults: map[string]any{}},
+			Options: map[string]any{
+				"calculate": false,
+				"color":     map[string]any{"mode": "scheme", "scheme": "Rainbow", "scale": "exponential", "exponent": 0.5},
+				"exemplars": map[string]any{"color": "rgba(255,0,255,0.7)"},
+			},
+		},
+		{
+			Type:    "timeseries",
+			Title:   "Failed polls",
+			GridPos: GridPos{H: 8, W: 12, X: 0, Y: 61},
+			Targets: target(fmt.Sprintf("increase(%s%s[1h])", MetricFetchFailures, sel), "{{initiative_id}}"),
+			FieldConfig: FieldConfig{Defaults: map[string]any{
+				"color": map[string]any{"mode": "palette-classic"},
+				"unit":  "none",
+			}},
+			Options: legendOptions(),
+		},
+		{
+			Type:    "stat",
+			Title:   "Last successful poll",
+			GridPos: GridPos{H: 8, W: 12, X: 12, Y: 61},
+			Targets: target(fmt.Sprintf("%s%s * 1000", MetricLastSuccess, sel), "{{initiative_id}}"),
+			FieldConfig: FieldConfig{Defaults: map[string]any{
+				"color": map[string]any{"mode": "fixed", "fixedColor": "text"},
+				"unit":  "dateTimeFromNow",
+			}},
+			Options: statOptions(),
+		},
+	}
+
+	for i := range panels {
+		panels[i].ID = i + 1
+		panels[i].FieldConfig.Overrides = []any{}
+
+		if panels[i].Datasource == nil && len(panels[i].Targets) > 0 {
+			panels[i].Datasource = prometheus
+		}
+	}
+
+	return Dashboard{
+		UID:           cfg.UID,
+		Title:         cfg.Title,
+		Editable:      true,
+		SchemaVersion: 40, //nolint:mnd // Grafana 11.
+		Timezone:      "browser",
+		Time:          DashboardRange{From: "now-3h", To: "now"},
+		Templating: Templating{List: []Variable{
+			{
+				Name:       VariableInitiative,
+				Label:      "Initiative ID",
+				Type:       "query",
+				Query:      fmt.Sprintf("label_values(%s, initiative_id)", MetricSignatures),
+				Definition: fmt.Sprintf("label_values(%s, initiative_id)", MetricSignatures),
+				Datasource: prometheus,
+				Refresh:    2, //nolint:mnd // on time range change.
+				Sort:       3, //nolint:mnd // alphabetical, case-insensitive.
+				Current:    map[string]any{},
+				Options:    []map[string]any{},
+			},
+			{
+				Name:    VariablePrometheus,
+				Label:   "Prometheus data source",
+				Type:    "datasource",
+				Query:   DatasourcePrometheus,
+				Refresh: 1,
+				Current: map[string]any{},
+				Options: []map[string]any{},
+			},
+			{
+				Name:    VariableLoki,
+				Label:   "Loki data source",
+				Type:    "datasource",
+				Query:   DatasourceLoki,
+				Refresh: 1,
+				Current: map[string]any{},
+				Options: []map[string]any{},
+			},
+		}},
+		Panels: panels,
+	}
+}
+
+// WriteDashboard writes the generated dashboard as indented JSON.
+func WriteDashboard(w io.Writer, cfg DashboardConfig) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	err := enc.Encode(GenerateDashboard(cfg))
+	if err != nil {
+		return fmt.Errorf("encode dashboard: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	eci "github.com/tvanriel/eci-prometheus-exporter"
+	"go.uber.org/zap/zaptest"
+)
+
+// descRecorder is a [prometheus.Registerer] that records the names of the registered metrics.
+type descRecorder struct {
+	names map[string]bool
+}
+
+var fqNamePattern = regexp.MustCompile(`fqName: "([^"]+)"`)
+
+func (d *descRecorder) Register(c prometheus.Collector) error {
+	ch := make(chan *prometheus.Desc)
+
+	go func() {
+		c.Describe(ch)
+		close(ch)
+	}()
+
+	for desc := range ch {
+		if m := fqNamePattern.FindStringSubmatch(desc.String()); m != nil {
+			d.names[m[1]] = true
+		}
+	}
+
+	return nil
+}
+
+func (d *descRecorder) MustRegister(cs ...prometheus.Collector) {
+	for _, c := range cs {
+		_ = d.Register(c)
+	}
+}
+
+func (d *descRecorder) Unregister(prometheus.Collector) bool { return false }
+
+// exposedMetrics returns the names of the series exposed by the exporter, including the histogram series.
+func exposedMetrics(t *testing.T) map[string]bool {
+	t.Helper()
+
+	app := eci.NewApplication(zaptest.NewLogger(t), "", nil, "", http.DefaultClient)
+	recorder := &descRecorder{names: map[string]bool{}}
+	app.MustRegisterWith(recorder)
+
+	names := map[string]bool{}
+
+	for name := range recorder.names {
+		names[name] = true
+
+		if name == eci.MetricAPIDuration {
+			for _, suffix := range []string{"_bucket", "_sum", "_count"} {
+				names[name+suffix] = true
+			}
+		}
+	}
+
+	return names
+}
+
+var metricPattern = regexp.MustCompile(`\beci_[a-z0-9_]+`)
+
+func TestGenerateDashboard_Metrics(t *testing.T) {
+	t.Parallel()
+
+	exposed := exposedMetrics(t)
+	require.Contains(t, exposed, eci.MetricSignatures)
+
+	dashboard := eci.GenerateDashboard(eci.DashboardConfig{Title: "ECI", LogSelector: `{job="eci"}`})
+	exprs := dashboard.PrometheusExprs()
+	require.NotEmpty(t, exprs)
+
+	for _, expr := range exprs {
+		metrics := metricPattern.FindAllString(expr, -1)
+		assert.NotEmpty(t, metrics, "expression without metric: %s", expr)
+
+		for _, m := range metrics {
+			assert.True(t, exposed[m], "metric %s is not exposed by the exporter: %s", m, expr)
+		}
+
+		assert.Contains(t, expr, "$"+eci.VariableInitiative)
+	}
+}
+
+func TestGenerateDashboard_Panels(t *testing.T) {
+	t.Parallel()
+
+	dashboard := eci.GenerateDashboard(eci.DashboardConfig{Title: "ECI", LogSelector: `{job="eci"}`})
+
+	variables := map[string]bool{}
+	for _, v := range dashboard.Templating.List {
+		variables[v.Name] = true
+	}
+
+	assert.Equal(t, map[string]bool{
+		eci.VariableInitiative: true,
+		eci.VariablePrometheus: true,
+		eci.VariableLoki:       true,
+	}, variables)
+
+	ids := map[int]bool{}
+
+	for _, p := range dashboard.Panels {
+		assert.False(t, ids[p.ID], "duplicate panel id %d", p.ID)
+		ids[p.ID] = true
+
+		assert.LessOrEqual(t, p.GridPos.X+p.GridPos.W, 24, "panel %q exceeds the grid", p.Title)
+	}
+}
+
+func TestWriteDashboard(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	err := eci.WriteDashboard(&buf, eci.DashboardConfig{Title: "ECI", UID: "eci", LogSelector: `{job="eci"}`})
+	require.NoError(t, err)
+
+	var doc map[string]any
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, "ECI", doc["title"])
+	assert.Equal(t, "eci", doc["uid"])
+	assert.True(t, strings.HasSuffix(buf.String(), "}\n"))
+}
@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"go.uber.org/zap"
+)
+
+// detectDecreases compares the country totals with the previous report of the initiative and counts every decrease.
+// Decreases are queued as events when NotifyDecreases is set. The ECI lowers totals when it removes invalid statements of support.
+func (a *Application) detectDecreases(registrationNumber RegistrationNumber, report *ProgressResponse, logger *zap.Logger) {
+	current := make(map[string]int, len(report.SOSReport.Entries))
+	for _, e := range report.SOSReport.Entries {
+		current[e.CountryCode] = e.Total
+	}
+
+	a.previousMu.Lock()
+	previous := a.previousTotals[registrationNumber]
+	a.previousTotals[registrationNumber] = current
+	a.previousMu.Unlock()
+
+	for countryCode, total := range current {
+		before, ok := previous[countryCode]
+		if !ok || total >= before {
+			continue
+		}
+
+		a.SignatureDecreases.WithLabelValues(registrationNumber.String(), countryCode).Inc()
+		a.SignatureDecreaseMagnitude.WithLabelValues(registrationNumber.String(), countryCode).Add(float64(before - total))
+
+		logger.Warn("Signature count decreased",
+			zap.String("country_code", countryCode),
+			zap.Int("before", before),
+			zap.Int("after", total),
+		)
+
+		if a.Events == nil || !a.NotifyDecreases {
+			continue
+		}
+
+		a.Events.Enqueue(NewDecreaseEvent(registrationNumber, countryCode, before, total))
+	}
+}
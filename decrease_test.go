@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"text/template"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	eci "github.com/tvanriel/eci-prometheus-exporter"
+	"go.uber.org/zap/zaptest"
+)
+
+// ServerRemovesSignatures answers with the default response first and with 811 fewer signatures in NL afterwards.
+func ServerRemovesSignatures(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var calls atomic.Int32
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) == 1 {
+			_, _ = w.Write([]byte(defaultResponse))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(strings.Replace(defaultResponse, `"NL","total":75811`, `"NL","total":75000`, 1)))
+	}))
+}
+
+func TestApplication_FetchAndUpdateMetricsDetectsDecreases(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		notify       bool
+		wantPayloads int
+	}{
+		"counts decreases": {
+			notify:       false,
+			wantPayloads: 0,
+		},
+		"notifies decreases": {
+			notify:       true,
+			wantPayloads: 1,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server := ServerRemovesSignatures(t)
+			defer server.Close()
+
+			recorder := &webhookRecorder{status: http.StatusOK}
+			webhook := httptest.NewServer(recorder)
+			defer webhook.Close()
+
+			state, err := eci.LoadState("")
+			require.NoError(t, err)
+
+			rn := *MustParseRegistrationNumber("ECI(2024)000007")
+			app := eci.NewApplication(zaptest.NewLogger(t), server.URL, []eci.RegistrationNumber{rn}, "", http.DefaultClient)
+			app.NotifyDecreases = tt.notify
+			app.Events = &eci.EventEngine{
+				State:  state,
+				Logger: zaptest.NewLogger(t),
+				Webhooks: []*eci.Webhook{{
+					URL:        webhook.URL,
+					Template:   template.Must(eci.ParseWebhookTemplate(`{{ .Type }} {{ .CountryCode }} {{ .Previous }} {{ .Value }}`)),
+					HTTPClient: http.DefaultClient,
+				}},
+			}
+
+			require.NoError(t, app.FetchAndUpdateMetrics(t.Context(), rn))
+			assert.InDelta(t, 0, testutil.ToFloat64(app.SignatureDecreases.WithLabelValues(rn.String(), "NL")), 0)
+
+			require.NoError(t, app.FetchAndUpdateMetrics(t.Context(), rn))
+			assert.InDelta(t, 1, testutil.ToFloat64(app.SignatureDecreases.WithLabelValues(rn.String(), "NL")), 0)
+			assert.InDelta(t, 811, testutil.ToFloat64(app.SignatureDecreaseMagnitude.WithLabelValues(rn.String(), "NL")), 0)
+			assert.InDelta(t, 0, testutil.ToFloat64(app.SignatureDecreases.WithLabelValues(rn.String(), "DE")), 0)
+
+			require.NoError(t, app.Events.Flush(t.Context()))
+			require.Len(t, recorder.payloads, tt.wantPayloads)
+
+			if tt.notify {
+				assert.Equal(t, "signature_decrease NL 75811 75000", recorder.payloads[0])
+			}
+		})
+	}
+}
@@ -7,13 +7,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"net"
+	"io"
 	"net/http"
-	"strings"
+	"os"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -36,6 +40,34 @@ type SOSReport struct {
 	Entries         []SOSEntry `json:"entry"`
 }
 
+// normalizeCountryCodes replaces the country codes of the entries with their ISO 3166 codes, so EL and GR, or UK and
+// GB, end up in the same series.
+func (r *SOSReport) normalizeCountryCodes() {
+	for i := range r.Entries {
+		r.Entries[i].CountryCode = Countries.Normalize(r.Entries[i].CountryCode)
+	}
+}
+
+// Names of the metrics exposed by the exporter.
+const (
+	MetricSignatures                 = "eci_signatures"
+	MetricSignatureThreshold         = "eci_signature_threshold"
+	MetricSignaturesPerCapita        = "eci_signatures_per_100k_inhabitants"
+	MetricSignatureShare             = "eci_signature_share_ratio"
+	MetricSignatureProgress          = "eci_signature_progress_ratio"
+	MetricPopulation                 = "eci_population"
+	MetricAPIDuration                = "eci_api_duration_seconds"
+	MetricSignatureDecreases         = "eci_signature_decreases_total"
+	MetricSignatureDecreaseMagnitude = "eci_signature_decrease_magnitude_total"
+	MetricReportInconsistency        = "eci_report_inconsistency"
+	MetricReportCountryIssues        = "eci_report_country_issues"
+	MetricFetchFailures              = "eci_fetch_failures_total"
+	MetricLastSuccess                = "eci_last_success_timestamp_seconds"
+	MetricCacheHits                  = "eci_api_cache_hits_total"
+	MetricCacheMisses                = "eci_api_cache_misses_total"
+	MetricCountryInfo                = "eci_country_info"
+)
+
 // Application contains the application logic.
 type Application struct {
 	Initiatives []RegistrationNumber
@@ -43,15 +75,56 @@ type Application struct {
 
 	Logger *zap.Logger
 
-	Address    string
-	Interval   time.Duration
-	HTTPClient *http.Client
+	Address        string
+	UnixSocketMode os.FileMode
+	Interval       time.Duration
+	HTTPClient     *http.Client
 
 	HTTPServer *http.Server
-
-	SignatureCount *prometheus.GaugeVec
-	SignatureGoal  *prometheus.GaugeVec
+	Mux        *http.ServeMux
+	Textfile   *Textfile
+	Sinks      []Sink
+	Events     *EventEngine
+	Prober     *Prober
+	Refresher  *Refresher
+	Scheduler  *Scheduler
+
+	// NotifyDecreases sends an event to the [EventEngine] when a country total decreases.
+	NotifyDecreases bool
+
+	// Reports exposes the signatures and thresholds of the latest report of every initiative.
+	Reports        *ReportCollector
 	APIDurationVec *prometheus.HistogramVec
+
+	// CountryInfo exposes the names of the countries to join on country_code.
+	CountryInfo *CountryInfoCollector
+
+	SignatureDecreases         *prometheus.CounterVec
+	SignatureDecreaseMagnitude *prometheus.CounterVec
+
+	ReportInconsistency *prometheus.GaugeVec
+	ReportCountryIssues *prometheus.GaugeVec
+
+	FetchFailures *prometheus.CounterVec
+	LastSuccess   *prometheus.GaugeVec
+
+	// InitiativeValid is 0 for initiatives that do not exist in the ECI register and 1 for those that do.
+	InitiativeValid *prometheus.GaugeVec
+
+	// Breakers stop polling initiatives while the ECI API keeps failing.
+	Breakers *Breakers
+
+	// Cache detects unchanged reports, counted in CacheHits and CacheMisses.
+	Cache       *ResponseCache
+	CacheHits   *prometheus.CounterVec
+	CacheMisses *prometheus.CounterVec
+
+	Tracer trace.Tracer
+
+	initiativesMu sync.RWMutex
+
+	previousMu     sync.Mutex
+	previousTotals map[RegistrationNumber]map[string]int
 }
 
 // NewApplication constructs an application from the configuration.
@@ -63,49 +136,146 @@ func NewApplication(
 	httpClient *http.Client,
 ) *Application {
 	var (
-		signatureCountVec = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Name: "eci_signatures",
-			Help: "Number of signatures collected by the European Citizens Initiative Per Country",
+		apiDurationVec = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    MetricAPIDuration,
+			Help:    "Duration of API calls to the ECI endpoint per initiative",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"initiative_id"})
+
+		signatureDecreasesVec = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: MetricSignatureDecreases,
+			Help: "Number of times the signature count of a country decreased",
 		}, []string{"initiative_id", "country_code"})
 
-		signatureGoalVec = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Name: "eci_signature_threshold",
-			Help: "Threshold number of signatures for the European Citizens Initiative",
+		signatureDecreaseMagnitudeVec = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: MetricSignatureDecreaseMagnitude,
+			Help: "Number of signatures removed from the count of a country",
 		}, []string{"initiative_id", "country_code"})
 
-		apiDurationVec = prometheus.NewHistogramVec(prometheus.HistogramOpts{
-			Name:    "eci_api_duration_seconds",
-			Help:    "Duration of API calls to the ECI endpoint per initiative",
-			Buckets: prometheus.DefBuckets,
+		reportInconsistencyVec = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: MetricReportInconsistency,
+			Help: "Total signatures minus the sum of the per-country signatures in the ECI report",
+		}, []string{"initiative_id"})
+
+		reportCountryIssuesVec = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: MetricReportCountryIssues,
+			Help: "Duplicated or unknown country codes in the ECI report",
+		}, []string{"initiative_id", "country_code", "issue"})
+
+		fetchFailuresVec = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: MetricFetchFailures,
+			Help: "Number of failed polls of the ECI API per initiative",
+		}, []string{"initiative_id"})
+
+		lastSuccessVec = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: MetricLastSuccess,
+			Help: "Unix timestamp of the last successful poll of the ECI API per initiative",
+		}, []string{"initiative_id"})
+
+		initiativeValidVec = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: MetricInitiativeValid,
+			Help: "Whether the initiative exists in the ECI register (1) or not (0)",
+		}, []string{"initiative_id"})
+
+		cacheHitsVec = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: MetricCacheHits,
+			Help: "Number of ECI API responses that were unchanged since the previous response, by matched validator",
+		}, []string{"initiative_id", "validator"})
+
+		cacheMissesVec = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: MetricCacheMisses,
+			Help: "Number of ECI API responses with a changed report",
 		}, []string{"initiative_id"})
 	)
 
 	sm := http.NewServeMux()
-	sm.Handle("/metrics", promhttp.Handler())
+	sm.Handle("/metrics", promhttp.InstrumentMetricHandler(
+		prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}),
+	))
 
 	server := &http.Server{
 		ReadTimeout: defaultReadTimeout,
 		Handler:     sm,
 	}
 
-	return &Application{
+	a := &Application{
 		Initiatives: initiatives,
 		APIURL:      apiURL,
 
-		Logger:     logger,
-		HTTPClient: httpClient,
-		Address:    address,
-		HTTPServer: server,
+		Logger:         logger,
+		HTTPClient:     httpClient,
+		Address:        address,
+		UnixSocketMode: defaultUnixSocketMode,
+		HTTPServer:     server,
+		Mux:            sm,
 
-		SignatureCount: signatureCountVec,
-		SignatureGoal:  signatureGoalVec,
+		Reports:        NewReportCollector(),
 		APIDurationVec: apiDurationVec,
+		CountryInfo:    NewCountryInfoCollector(Countries),
+
+		SignatureDecreases:         signatureDecreasesVec,
+		SignatureDecreaseMagnitude: signatureDecreaseMagnitudeVec,
+
+		ReportInconsistency: reportInconsistencyVec,
+		ReportCountryIssues: reportCountryIssuesVec,
+
+		FetchFailures:   fetchFailuresVec,
+		LastSuccess:     lastSuccessVec,
+		InitiativeValid: initiativeValidVec,
+
+		Breakers: NewBreakers(defaultBreakerFailures, defaultBreakerCooldown, initiatives),
+
+		Cache:       NewResponseCache(),
+		CacheHits:   cacheHitsVec,
+		CacheMisses: cacheMissesVec,
+
+		Tracer: otel.Tracer(TracerName),
+
+		previousTotals: map[RegistrationNumber]map[string]int{},
 	}
+
+	a.Prober = NewProber(a, defaultProbeCacheTTL, defaultProbeTimeout)
+	sm.Handle("/probe", a.Prober)
+	sm.HandleFunc("/healthz", a.Health)
+
+	a.Refresher = NewRefresher(a, defaultRefreshTimeout)
+	sm.Handle("/api/v1/refresh", a.Refresher)
+	sm.Handle("/api/v1/refresh/", a.Refresher)
+
+	return a
 }
 
 // MustRegisterWith registers the application metrics with the given prometheus registerer.
 func (a *Application) MustRegisterWith(r prometheus.Registerer) {
-	r.MustRegister(a.APIDurationVec, a.SignatureCount, a.SignatureGoal)
+	r.MustRegister(a.Reports, a.CountryInfo, a.Breakers.State)
+
+	for _, vec := range a.initiativeVecs() {
+		r.MustRegister(vec)
+	}
+}
+
+// initiativeVec is a metric vector with an initiative_id label.
+type initiativeVec interface {
+	prometheus.Collector
+	DeletePartialMatch(labels prometheus.Labels) int
+}
+
+// initiativeVecs returns the metric vectors of the application with series per initiative, which are registered by
+// [Application.MustRegisterWith] and deleted when an initiative is removed.
+func (a *Application) initiativeVecs() []initiativeVec {
+	return []initiativeVec{
+		a.APIDurationVec,
+		a.SignatureDecreases,
+		a.SignatureDecreaseMagnitude,
+		a.ReportInconsistency,
+		a.ReportCountryIssues,
+		a.FetchFailures,
+		a.LastSuccess,
+		a.InitiativeValid,
+		a.CacheHits,
+		a.CacheMisses,
+	}
 }
 
 // ErrNon200 is returned when a non-200 response was given by the ECI API.
@@ -113,74 +283,206 @@ var ErrNon200 = errors.New("Non-200 response")
 
 // FetchAndUpdateMetrics performs the request and puts the result in the metrics.
 func (a *Application) FetchAndUpdateMetrics(ctx context.Context, registrationNumber RegistrationNumber) error {
-	data, err := a.Fetch(ctx, registrationNumber)
+	ctx, span := a.Tracer.Start(ctx, "eci.poll", trace.WithAttributes(
+		attribute.String("eci.initiative_id", registrationNumber.String()),
+	))
+	defer span.End()
+
+	data, unchanged, err := a.fetch(ctx, registrationNumber, a.Cache)
 	if err != nil {
+		recordSpanError(span, err)
+		a.FetchFailures.WithLabelValues(registrationNumber.String()).Inc()
+
+		if errors.Is(err, ErrNotFound) {
+			a.InitiativeValid.WithLabelValues(registrationNumber.String()).Set(0)
+		}
+
 		return err
 	}
 
-	logger := a.Logger.With(zap.String("initiative_id", registrationNumber.String()))
+	a.InitiativeValid.WithLabelValues(registrationNumber.String()).Set(1)
+
+	logger := a.Logger.With(zap.String("initiative_id", registrationNumber.String())).With(traceFields(ctx)...)
+
+	if unchanged {
+		span.SetAttributes(attribute.Bool("eci.unchanged", true))
+		logger.Debug("Report unchanged, skipping update")
+		a.LastSuccess.WithLabelValues(registrationNumber.String()).SetToCurrentTime()
+
+		// Sinks still see every successful poll, e.g. to retry the delivery of events.
+		a.notifySinks(ctx, registrationNumber, data)
+
+		return nil
+	}
+
+	_, updateSpan := a.Tracer.Start(ctx, "eci.update_metrics")
+	defer updateSpan.End()
 
 	registrationDate, err := time.Parse("02/01/2006", data.RegistrationDate)
 	if err != nil {
 		logger.Error("failed to parse registration date.", zap.Error(err))
 
-		return fmt.Errorf("cannot parse registration date: %w", err)
+		err = fmt.Errorf("cannot parse registration date: %w", err)
+		// Do not treat the same broken report as a successful, unchanged poll next time.
+		a.Cache.Delete(registrationNumber)
+		recordSpanError(updateSpan, err)
+		recordSpanError(span, err)
+		a.FetchFailures.WithLabelValues(registrationNumber.String()).Inc()
+
+		return err
 	}
 
 	th := GetThresholds(registrationDate)
 
-	for _, e := range data.SOSReport.Entries {
-		a.SignatureCount.WithLabelValues(
-			registrationNumber.String(), 
-			e.CountryCode,
-		).Set(float64(e.Total))
-		a.SignatureGoal.WithLabelValues(
-			registrationNumber.String(), 
-			e.CountryCode,
-		).Set(float64(th[MemberCountryCode(strings.ToLower(e.CountryCode))]))
-	}
+	a.checkConsistency(registrationNumber, data, th, logger)
+	a.detectDecreases(registrationNumber, data, logger)
+
+	a.Reports.Set(registrationNumber, data, th)
+	a.LastSuccess.WithLabelValues(registrationNumber.String()).SetToCurrentTime()
+
+	a.notifySinks(ctx, registrationNumber, data)
 
 	return nil
 }
 
-// Fetch performs the API call to the ECI.
+// Fetch performs the API call to the ECI. It does not use the response cache of the polls, so the next poll still
+// sees a changed report as changed.
 func (a *Application) Fetch(ctx context.Context, registrationNumber RegistrationNumber) (*ProgressResponse, error) {
+	data, _, err := a.fetch(ctx, registrationNumber, nil)
+
+	return data, err
+}
+
+// fetch performs a conditional API call to the ECI and reports whether the report is unchanged since the
+// previous call. Unchanged reports are returned from the cache. Without a cache every call downloads the report.
+//
+//nolint:funlen,cyclop // tracing and logging of every step.
+func (a *Application) fetch(
+	ctx context.Context,
+	registrationNumber RegistrationNumber,
+	cache *ResponseCache,
+) (*ProgressResponse, bool, error) {
+	ctx, span := a.Tracer.Start(ctx, "eci.fetch")
+	defer span.End()
+
 	apiURL := a.eciAPIURL(registrationNumber)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("make request: %w", err)
+		recordSpanError(span, err)
+
+		return nil, false, fmt.Errorf("make request: %w", err)
 	}
 
-	logger := a.Logger.With(zap.String("initiative_id", registrationNumber.String()))
+	logger := a.Logger.With(zap.String("initiative_id", registrationNumber.String())).With(traceFields(ctx)...)
 
-	timer := prometheus.NewTimer(a.APIDurationVec.WithLabelValues(registrationNumber.String()))
+	// Probes of initiatives that are not polled must not create breakers or series for every target.
+	tracked := a.Tracked(registrationNumber)
+
+	var breakers *Breakers
+	if tracked {
+		breakers = a.Breakers
+	}
+
+	err = breakers.Allow(registrationNumber)
+	if err != nil {
+		logger.Warn("Not calling the ECI API", zap.Error(err))
+		recordSpanError(span, err)
+
+		return nil, false, err
+	}
+
+	if cache != nil {
+		cache.SetConditionalHeaders(registrationNumber, req)
+	}
+
+	start := time.Now()
 
 	resp, err := a.HTTPClient.Do(req)
+	if err == nil {
+		defer resp.Body.Close() //nolint:errcheck // don't really care.
+	}
 
-	duration := timer.ObserveDuration()
+	duration := time.Since(start)
+
+	if tracked {
+		a.observeAPIDuration(ctx, registrationNumber, duration)
+	}
 
 	if err != nil {
 		logger.Error("Error fetching ECI API", zap.Error(err))
+		recordSpanError(span, err)
+		breakers.Failure(registrationNumber)
 
-		return nil, fmt.Errorf("doing request: %w", err)
+		return nil, false, fmt.Errorf("doing request: %w", err)
 	}
 
-	defer resp.Body.Close() //nolint:errcheck // don't really care.
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+
+	if resp.StatusCode == http.StatusNotModified && cache != nil {
+		if data, validator, ok := cache.NotModified(registrationNumber); ok {
+			breakers.Success(registrationNumber)
+			a.CacheHits.WithLabelValues(registrationNumber.String(), validator).Inc()
+			logger.Info("ECI stats not modified", zap.Duration("duration", duration))
+
+			return data, true, nil
+		}
+	}
 
 	if resp.StatusCode != http.StatusOK {
-		logger.Error("Non-200 response", zap.Int("status_code", resp.StatusCode))
+		err = ErrNon200
+		if resp.StatusCode == http.StatusNotFound {
+			err = ErrNotFound
+		}
+
+		logger.Error("Non-200 response", zap.Int("status_code", resp.StatusCode), zap.Error(err))
+		recordSpanError(span, err)
+		breakers.Failure(registrationNumber)
+
+		return nil, false, err
+	}
+
+	breakers.Success(registrationNumber)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logger.Error("Failed to read response", zap.Error(err))
+		recordSpanError(span, err)
+
+		return nil, false, fmt.Errorf("read response: %w", err)
+	}
+
+	if cache != nil {
+		if data, ok := cache.Lookup(registrationNumber, body); ok {
+			a.CacheHits.WithLabelValues(registrationNumber.String(), CacheHitBodyHash).Inc()
+			logger.Info("ECI stats unchanged", zap.Duration("duration", duration))
 
-		return nil, ErrNon200
+			return data, true, nil
+		}
+
+		a.CacheMisses.WithLabelValues(registrationNumber.String()).Inc()
 	}
 
 	data := &ProgressResponse{}
 
-	err = json.NewDecoder(resp.Body).Decode(data)
+	_, decodeSpan := a.Tracer.Start(ctx, "eci.decode_json")
+
+	err = json.Unmarshal(body, data)
 	if err != nil {
 		logger.Error("Failed to decode JSON", zap.Error(err))
+		recordSpanError(decodeSpan, err)
+		decodeSpan.End()
+		recordSpanError(span, err)
 
-		return nil, fmt.Errorf("decode json: %w", err)
+		return nil, false, fmt.Errorf("decode json: %w", err)
+	}
+
+	decodeSpan.End()
+
+	data.SOSReport.normalizeCountryCodes()
+
+	if cache != nil {
+		cache.Store(registrationNumber, resp, body, data)
 	}
 
 	logger.Info("Fetched ECI stats",
@@ -188,47 +490,75 @@ func (a *Application) Fetch(ctx context.Context, registrationNumber Registration
 		zap.Duration("duration", duration),
 	)
 
-	return data, nil
+	return data, false, nil
 }
 
-// Serve starts the HTTP server.
-func (a *Application) Serve() error {
-	a.Logger.Info("Serving Prometheus metrics", zap.String("endpoint", "/metrics"))
+// observeAPIDuration records the API call duration, with the trace ID as exemplar when the span is sampled.
+func (a *Application) observeAPIDuration(
+	ctx context.Context,
+	registrationNumber RegistrationNumber,
+	duration time.Duration,
+) {
+	observer := a.APIDurationVec.WithLabelValues(registrationNumber.String())
 
-	l, err := net.Listen("tcp", a.Address)
-	if err != nil {
-		a.Logger.Error("Cannot listen on configured port", zap.String("address", a.Address))
+	sc := trace.SpanContextFromContext(ctx)
+	if eo, ok := observer.(prometheus.ExemplarObserver); ok && sc.IsSampled() {
+		eo.ObserveWithExemplar(duration.Seconds(), prometheus.Labels{"trace_id": sc.TraceID().String()})
 
-		return fmt.Errorf("start application listener: %w", err)
+		return
 	}
 
-	err = a.HTTPServer.Serve(l)
+	observer.Observe(duration.Seconds())
+}
+
+// Serve starts the HTTP server.
+func (a *Application) Serve() error {
+	listeners, err := a.Listen()
 	if err != nil {
-		a.Logger.Error("HTTP server failed", zap.Error(err))
+		a.Logger.Error("Cannot listen on configured address", zap.String("address", a.Address), zap.Error(err))
 
-		return fmt.Errorf("serve application server: %w", err)
+		return fmt.Errorf("start application listener: %w", err)
 	}
 
-	return nil
+	return a.ServeListeners(listeners)
 }
 
 // StartPolling polls when the given ticker ticks.
 func (a *Application) StartPolling(registrationNumber RegistrationNumber, ticker *time.Ticker, timeout time.Duration) {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	_ = a.FetchAndUpdateMetrics(ctx, registrationNumber)
-
-	cancel()
+	_ = a.poll(context.Background(), registrationNumber, timeout)
 
 	for range ticker.C {
-		ctx, cancel := context.WithTimeout(context.Background(), timeout)
-		_ = a.FetchAndUpdateMetrics(ctx, registrationNumber)
+		_ = a.poll(context.Background(), registrationNumber, timeout)
+	}
+}
+
+// poll updates the metrics for one initiative and writes the textfile when configured. It returns the error of the
+// fetch.
+func (a *Application) poll(ctx context.Context, registrationNumber RegistrationNumber, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-		cancel()
+	fetchErr := a.FetchAndUpdateMetrics(ctx, registrationNumber)
+
+	if !a.Tracked(registrationNumber) {
+		// Removed while it was being polled.
+		a.deleteSeries(registrationNumber)
+	}
+
+	if a.Textfile == nil {
+		return fetchErr
+	}
+
+	err := a.Textfile.Write()
+	if err != nil {
+		a.Logger.Error("Cannot write textfile", zap.String("path", a.Textfile.Path), zap.Error(err))
 	}
+
+	return fetchErr
 }
 
 func (a *Application) eciAPIURL(registrationNumber RegistrationNumber) string {
-return fmt.Sprintf(
+	return fmt.Sprintf(
 		"%s/core/api/register/details/%s/%s",
 		a.APIURL,
 		registrationNumber.Year,
@@ -239,4 +569,34 @@ return fmt.Sprintf(
 const (
 	defaultInterval    = 5 * time.Minute
 	defaultReadTimeout = 3 * time.Second
+
+	defaultShutdownTimeout = 10 * time.Second
+
+	defaultUnixSocketMode = 0o660
+
+	defaultProbeCacheTTL = time.Minute
+	defaultProbeTimeout  = 10 * time.Second
+
+	defaultRefreshTimeout  = 30 * time.Second
+	defaultValidateTimeout = 30 * time.Second
+
+	defaultAdaptiveWindow = 2 * time.Hour
+	defaultMaxStaleness   = 6 * time.Hour
+
+	defaultBreakerFailures = 5
+	defaultBreakerCooldown = time.Minute
+
+	defaultConcurrency = 4
+	defaultRateLimit   = 1.0
+
+	defaultDialTimeout           = 10 * time.Second
+	defaultTLSHandshakeTimeout   = 10 * time.Second
+	defaultResponseHeaderTimeout = 30 * time.Second
+	defaultKeepAlive             = 30 * time.Second
+	defaultIdleConnTimeout       = 90 * time.Second
+	defaultMaxIdleConnsPerHost   = 4
+
+	defaultSinkBatchSize     = 500
+	defaultSinkFlushInterval = 30 * time.Second
+	defaultSinkRetries       = 3
 )
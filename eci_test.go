@@ -209,14 +209,17 @@ func TestApplication_Serve(t *testing.T) {
 		_ = app.Serve()
 	}()
 
-	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://localhost:12415/metrics", nil)
-	require.NoError(t, err)
-	resp, err := http.DefaultClient.Do(req)
+	// Serve listens in the goroutine, so the first requests can be refused.
+	assert.EventuallyWithT(t, func(collect *assert.CollectT) {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://localhost:12415/metrics", nil)
+		require.NoError(collect, err)
+		resp, err := http.DefaultClient.Do(req)
 
-	require.NoError(t, err)
-	assert.NotEmpty(t, resp.Body)
+		require.NoError(collect, err)
+		assert.NotEmpty(collect, resp.Body)
 
-	_ = resp.Body.Close()
+		_ = resp.Body.Close()
+	}, time.Second, 50*time.Millisecond)
 }
 
 //nolint:paralleltest // do not run me parallel.
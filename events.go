@@ -0,0 +1,300 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Types of [Event].
+const (
+	EventThresholdCrossed  = "threshold_crossed"
+	EventMilestone         = "milestone"
+	EventCountryCriterion  = "country_criterion"
+	EventSignatureDecrease = "signature_decrease"
+)
+
+// MinimumCountries is the number of member states that have to reach their threshold.
+const MinimumCountries = 7
+
+// ErrInvalidMilestone is returned when a milestone is not a positive number.
+var ErrInvalidMilestone = errors.New("invalid milestone")
+
+// ParseMilestones reads a comma-separated list of milestones.
+func ParseMilestones(milestones string) ([]int, error) {
+	if milestones == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(milestones, ",")
+	result := make([]int, 0, len(parts))
+
+	for _, p := range parts {
+		m, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil || m <= 0 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidMilestone, p)
+		}
+
+		result = append(result, m)
+	}
+
+	return result, nil
+}
+
+// Event is a notable change in the progress of an initiative.
+type Event struct {
+	Type         string    `json:"type"`
+	InitiativeID string    `json:"initiative_id"`
+	CountryCode  string    `json:"country_code,omitempty"`
+	Value        int       `json:"value"`
+	Threshold    int       `json:"threshold,omitempty"`
+	Previous     int       `json:"previous,omitempty"`
+	Message      string    `json:"message"`
+	Time         time.Time `json:"time"`
+}
+
+// Key identifies the event for de-duplication.
+func (e Event) Key() string {
+	if e.Type == EventSignatureDecrease {
+		return fmt.Sprintf("%s/%s/%s/%d-%d", e.InitiativeID, e.Type, e.CountryCode, e.Previous, e.Value)
+	}
+
+	return fmt.Sprintf("%s/%s/%s/%d", e.InitiativeID, e.Type, e.CountryCode, e.Threshold)
+}
+
+// NewDecreaseEvent creates the event for a decreased country total.
+func NewDecreaseEvent(registrationNumber RegistrationNumber, countryCode string, before, after int) Event {
+	return Event{
+		Type:         EventSignatureDecrease,
+		InitiativeID: registrationNumber.String(),
+		CountryCode:  countryCode,
+		Value:        after,
+		Previous:     before,
+		Message: fmt.Sprintf(
+			"%s lost %d signatures in %s (%d to %d)",
+			registrationNumber.String(), before-after, countryCode, before, after,
+		),
+		Time: time.Now(),
+	}
+}
+
+// DetectEvents returns every threshold, milestone and country criterion the report has reached.
+func DetectEvents(registrationNumber RegistrationNumber, report *ProgressResponse, milestones []int) []Event {
+	var (
+		initiativeID = registrationNumber.String()
+		now          = time.Now()
+		events       []Event
+	)
+
+	for _, m := range milestones {
+		if report.SOSReport.TotalSignatures < m {
+			continue
+		}
+
+		events = append(events, Event{
+			Type:         EventMilestone,
+			InitiativeID: initiativeID,
+			Value:        report.SOSReport.TotalSignatures,
+			Threshold:    m,
+			Message:      fmt.Sprintf("%s passed %d signatures", initiativeID, m),
+			Time:         now,
+		})
+	}
+
+	registrationDate, err := time.Parse("02/01/2006", report.RegistrationDate)
+	if err != nil {
+		return events
+	}
+
+	th := GetThresholds(registrationDate)
+	countries := 0
+
+	for _, e := range report.SOSReport.Entries {
+		goal, ok := th[Countries.MemberCountryCode(e.CountryCode)]
+		if !ok || e.Total < goal {
+			continue
+		}
+
+		countries++
+
+		events = append(events, Event{
+			Type:         EventThresholdCrossed,
+			InitiativeID: initiativeID,
+			CountryCode:  e.CountryCode,
+			Value:        e.Total,
+			Threshold:    goal,
+			Message:      fmt.Sprintf("%s reached the threshold of %d signatures in %s", initiativeID, goal, e.CountryCode),
+			Time:         now,
+		})
+	}
+
+	if countries >= MinimumCountries {
+		events = append(events, Event{
+			Type:         EventCountryCriterion,
+			InitiativeID: initiativeID,
+			Value:        countries,
+			Threshold:    MinimumCountries,
+			Message:      fmt.Sprintf("%s reached the threshold in %d member states", initiativeID, countries),
+			Time:         now,
+		})
+	}
+
+	return events
+}
+
+// EventEngine detects events in the reports and delivers every event once to every webhook.
+// It implements [Sink] so it is invoked after every successful poll. The events are delivered by [EventEngine.Run],
+// so slow or failing webhooks do not delay the polls.
+type EventEngine struct {
+	Milestones []int
+	Webhooks   []*Webhook
+	State      *State
+	Logger     *zap.Logger
+
+	mu      sync.Mutex
+	queue   []Event
+	pending map[string]bool // keys of the queued events and the events being delivered
+	wake    chan struct{}
+}
+
+// Write implements [Sink].
+//
+// The first report of an initiative only records the events that already happened, so that a newly tracked
+// initiative does not flood the webhooks. The other events that have not been delivered yet are queued, failed
+// events are queued again on the next poll.
+func (e *EventEngine) Write(_ context.Context, registrationNumber RegistrationNumber, report *ProgressResponse) error {
+	initiativeID := registrationNumber.String()
+	events := DetectEvents(registrationNumber, report, e.Milestones)
+
+	if !e.State.Seen(initiativeID) {
+		for _, event := range events {
+			err := e.State.MarkFired(event.Key(), event.Time)
+			if err != nil {
+				return err
+			}
+		}
+
+		return e.State.MarkSeen(initiativeID)
+	}
+
+	for _, event := range events {
+		if !e.State.Fired(event.Key()) {
+			e.Enqueue(event)
+		}
+	}
+
+	return nil
+}
+
+// Enqueue queues the event for delivery, unless it is already queued or being delivered.
+func (e *EventEngine) Enqueue(event Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.pending[event.Key()] {
+		return
+	}
+
+	if e.pending == nil {
+		e.pending = map[string]bool{}
+	}
+
+	e.pending[event.Key()] = true
+	e.queue = append(e.queue, event)
+
+	select {
+	case e.wakeup() <- struct{}{}:
+	default:
+	}
+}
+
+// wakeup returns the channel that signals [EventEngine.Run] to deliver the queue. The caller must hold the lock.
+func (e *EventEngine) wakeup() chan struct{} {
+	if e.wake == nil {
+		e.wake = make(chan struct{}, 1)
+	}
+
+	return e.wake
+}
+
+// Run delivers the queued events until the context is cancelled.
+func (e *EventEngine) Run(ctx context.Context) {
+	e.mu.Lock()
+	wake := e.wakeup()
+	e.mu.Unlock()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-wake:
+			_ = e.Flush(ctx)
+		}
+	}
+}
+
+// Flush delivers all queued events.
+func (e *EventEngine) Flush(ctx context.Context) error {
+	e.mu.Lock()
+	events := e.queue
+	e.queue = nil
+	e.mu.Unlock()
+
+	var errs []error
+
+	for _, event := range events {
+		errs = append(errs, e.Notify(ctx, event))
+
+		e.mu.Lock()
+		delete(e.pending, event.Key())
+		e.mu.Unlock()
+	}
+
+	return errors.Join(errs...)
+}
+
+// Notify delivers the event to the webhooks that have not accepted it yet. Every accepted delivery is recorded,
+// and the event is recorded as delivered when all webhooks accepted it.
+func (e *EventEngine) Notify(ctx context.Context, event Event) error {
+	e.Logger.Info("Event",
+		zap.String("type", event.Type),
+		zap.String("initiative_id", event.InitiativeID),
+		zap.String("message", event.Message),
+	)
+
+	var errs []error
+
+	for _, w := range e.Webhooks {
+		if e.State.Delivered(event.Key(), w.URL) {
+			continue
+		}
+
+		err := w.Deliver(ctx, event)
+		if err != nil {
+			e.Logger.Error("Cannot deliver event", zap.String("url", w.URL), zap.String("key", event.Key()), zap.Error(err))
+
+			errs = append(errs, err)
+
+			continue
+		}
+
+		err = e.State.MarkDelivered(event.Key(), w.URL, time.Now())
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return e.State.MarkFired(event.Key(), event.Time)
+}
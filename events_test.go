@@ -0,0 +1,335 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	eci "github.com/tvanriel/eci-prometheus-exporter"
+	"go.uber.org/zap/zaptest"
+)
+
+func eventKeys(events []eci.Event) []string {
+	keys := make([]string, 0, len(events))
+	for _, e := range events {
+		keys = append(keys, e.Key())
+	}
+
+	return keys
+}
+
+func TestParseMilestones(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		milestones string
+		want       []int
+		wantErr    assert.ErrorAssertionFunc
+	}{
+		"empty":        {milestones: "", want: nil, wantErr: assert.NoError},
+		"list":         {milestones: "100000, 1000000", want: []int{100000, 1000000}, wantErr: assert.NoError},
+		"not a number": {milestones: "lots", want: nil, wantErr: errContains("invalid milestone")},
+		"negative":     {milestones: "-1", want: nil, wantErr: errContains("invalid milestone")},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, gotErr := eci.ParseMilestones(tt.milestones)
+
+			tt.wantErr(t, gotErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDetectEvents(t *testing.T) {
+	t.Parallel()
+
+	events := eci.DetectEvents(*MustParseRegistrationNumber("ECI(2024)000007"), testReport(t), []int{100000, 2000000})
+	keys := eventKeys(events)
+
+	assert.Contains(t, keys, "ECI(2024)000007/milestone//100000")
+	assert.NotContains(t, keys, "ECI(2024)000007/milestone//2000000")
+	assert.Contains(t, keys, "ECI(2024)000007/threshold_crossed/NL/20445")
+	assert.NotContains(t, keys, "ECI(2024)000007/threshold_crossed/MT/4230")
+	assert.Contains(t, keys, "ECI(2024)000007/country_criterion//7")
+}
+
+// webhookRecorder records the payloads posted to it and answers with status.
+type webhookRecorder struct {
+	mu       sync.Mutex
+	status   int
+	payloads []string
+}
+
+func (w *webhookRecorder) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+
+	w.payloads = append(w.payloads, string(body))
+	rw.WriteHeader(w.status)
+}
+
+func TestEventEngine_Write(t *testing.T) {
+	t.Parallel()
+
+	recorder := &webhookRecorder{status: http.StatusOK}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	state, err := eci.LoadState(statePath)
+	require.NoError(t, err)
+
+	engine := &eci.EventEngine{
+		Milestones: []int{1000000, 2000000},
+		State:      state,
+		Logger:     zaptest.NewLogger(t),
+		Webhooks: []*eci.Webhook{{
+			URL:        server.URL,
+			Template:   template.Must(eci.ParseWebhookTemplate(`{{ .Type }} {{ .Threshold }}`)),
+			HTTPClient: http.DefaultClient,
+		}},
+	}
+
+	rn := *MustParseRegistrationNumber("ECI(2024)000007")
+	report := testReport(t)
+
+	require.NoError(t, engine.Write(t.Context(), rn, report))
+	require.NoError(t, engine.Flush(t.Context()))
+	assert.Empty(t, recorder.payloads, "first report only seeds the state")
+
+	report.SOSReport.TotalSignatures = 2000001
+	require.NoError(t, engine.Write(t.Context(), rn, report))
+	require.NoError(t, engine.Write(t.Context(), rn, report))
+	assert.Empty(t, recorder.payloads, "events are delivered outside of the poll")
+
+	require.NoError(t, engine.Flush(t.Context()))
+	require.NoError(t, engine.Write(t.Context(), rn, report))
+	require.NoError(t, engine.Flush(t.Context()))
+	assert.Equal(t, []string{"milestone 2000000"}, recorder.payloads)
+
+	restored, err := eci.LoadState(statePath)
+	require.NoError(t, err)
+	assert.True(t, restored.Fired("ECI(2024)000007/milestone//2000000"))
+}
+
+func TestEventEngine_WriteRetriesFailedDeliveries(t *testing.T) {
+	t.Parallel()
+
+	recorder := &webhookRecorder{status: http.StatusInternalServerError}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	state, err := eci.LoadState("")
+	require.NoError(t, err)
+	require.NoError(t, state.MarkSeen("ECI(2024)000007"))
+
+	engine := &eci.EventEngine{
+		Milestones: []int{1000000},
+		State:      state,
+		Logger:     zaptest.NewLogger(t),
+		Webhooks: []*eci.Webhook{{
+			URL:        server.URL,
+			Template:   template.Must(eci.ParseWebhookTemplate(eci.DefaultWebhookTemplate)),
+			HTTPClient: http.DefaultClient,
+			Retries:    1,
+		}},
+	}
+
+	rn := *MustParseRegistrationNumber("ECI(2024)000007")
+
+	require.NoError(t, engine.Write(t.Context(), rn, &eci.ProgressResponse{SOSReport: eci.SOSReport{TotalSignatures: 1000000}}))
+	require.Error(t, engine.Flush(t.Context()))
+	assert.Len(t, recorder.payloads, 2)
+	assert.False(t, state.Fired("ECI(2024)000007/milestone//1000000"))
+
+	recorder.mu.Lock()
+	recorder.status = http.StatusOK
+	recorder.mu.Unlock()
+
+	require.NoError(t, engine.Flush(t.Context()), "failed events are queued again by the next poll")
+	assert.Len(t, recorder.payloads, 2)
+
+	require.NoError(t, engine.Write(t.Context(), rn, &eci.ProgressResponse{SOSReport: eci.SOSReport{TotalSignatures: 1000000}}))
+	require.NoError(t, engine.Flush(t.Context()))
+	assert.Len(t, recorder.payloads, 3)
+	assert.Contains(t, recorder.payloads[2], `"type":"milestone"`)
+	assert.True(t, state.Fired("ECI(2024)000007/milestone//1000000"))
+}
+
+func TestApplication_FetchAndUpdateMetricsRetriesEventsOfUnchangedReports(t *testing.T) {
+	t.Parallel()
+
+	var notModified atomic.Int32
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(defaultResponse))
+	}))
+	defer api.Close()
+
+	recorder := &webhookRecorder{status: http.StatusInternalServerError}
+	webhook := httptest.NewServer(recorder)
+	defer webhook.Close()
+
+	state, err := eci.LoadState("")
+	require.NoError(t, err)
+	require.NoError(t, state.MarkSeen("ECI(2024)000007"))
+
+	engine := &eci.EventEngine{
+		Milestones: []int{1000000},
+		State:      state,
+		Logger:     zaptest.NewLogger(t),
+		Webhooks: []*eci.Webhook{{
+			URL:        webhook.URL,
+			Template:   template.Must(eci.ParseWebhookTemplate(eci.DefaultWebhookTemplate)),
+			HTTPClient: http.DefaultClient,
+		}},
+	}
+
+	rn := *MustParseRegistrationNumber("ECI(2024)000007")
+	app := eci.NewApplication(zaptest.NewLogger(t), api.URL, []eci.RegistrationNumber{rn}, "", http.DefaultClient)
+	app.Sinks = []eci.Sink{engine}
+
+	const key = "ECI(2024)000007/milestone//1000000"
+
+	require.NoError(t, app.FetchAndUpdateMetrics(t.Context(), rn))
+	require.Error(t, engine.Flush(t.Context()))
+	assert.False(t, state.Fired(key))
+
+	recorder.mu.Lock()
+	recorder.status = http.StatusOK
+	recorder.mu.Unlock()
+
+	require.NoError(t, app.FetchAndUpdateMetrics(t.Context(), rn))
+	require.NoError(t, engine.Flush(t.Context()))
+	assert.Equal(t, int32(1), notModified.Load(), "the second poll is unchanged")
+	assert.True(t, state.Fired(key), "the unchanged poll retries the failed event")
+}
+
+func TestEventEngine_FlushRetriesOnlyFailedWebhooks(t *testing.T) {
+	t.Parallel()
+
+	accepting := &webhookRecorder{status: http.StatusOK}
+	acceptingServer := httptest.NewServer(accepting)
+	defer acceptingServer.Close()
+
+	failing := &webhookRecorder{status: http.StatusInternalServerError}
+	failingServer := httptest.NewServer(failing)
+	defer failingServer.Close()
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	state, err := eci.LoadState(statePath)
+	require.NoError(t, err)
+	require.NoError(t, state.MarkSeen("ECI(2024)000007"))
+
+	tmpl := template.Must(eci.ParseWebhookTemplate(`{{ .Type }} {{ .Threshold }}`))
+	engine := &eci.EventEngine{
+		Milestones: []int{1000000},
+		State:      state,
+		Logger:     zaptest.NewLogger(t),
+		Webhooks: []*eci.Webhook{
+			{URL: acceptingServer.URL, Template: tmpl, HTTPClient: http.DefaultClient},
+			{URL: failingServer.URL, Template: tmpl, HTTPClient: http.DefaultClient},
+		},
+	}
+
+	const key = "ECI(2024)000007/milestone//1000000"
+
+	rn := *MustParseRegistrationNumber("ECI(2024)000007")
+	report := &eci.ProgressResponse{SOSReport: eci.SOSReport{TotalSignatures: 1000000}}
+
+	require.NoError(t, engine.Write(t.Context(), rn, report))
+	require.Error(t, engine.Flush(t.Context()))
+	assert.Len(t, accepting.payloads, 1)
+	assert.Len(t, failing.payloads, 1)
+	assert.False(t, state.Fired(key))
+
+	restored, err := eci.LoadState(statePath)
+	require.NoError(t, err)
+	assert.True(t, restored.Delivered(key, acceptingServer.URL))
+	assert.False(t, restored.Delivered(key, failingServer.URL))
+
+	failing.mu.Lock()
+	failing.status = http.StatusOK
+	failing.mu.Unlock()
+
+	require.NoError(t, engine.Write(t.Context(), rn, report))
+	require.NoError(t, engine.Flush(t.Context()))
+	assert.Len(t, accepting.payloads, 1, "accepted deliveries are not repeated")
+	assert.Len(t, failing.payloads, 2)
+	assert.True(t, state.Fired(key))
+	assert.False(t, state.Delivered(key, acceptingServer.URL), "deliveries are forgotten once the event is delivered")
+}
+
+func TestEventEngine_Run(t *testing.T) {
+	t.Parallel()
+
+	recorder := &webhookRecorder{status: http.StatusOK}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	state, err := eci.LoadState("")
+	require.NoError(t, err)
+	require.NoError(t, state.MarkSeen("ECI(2024)000007"))
+
+	engine := &eci.EventEngine{
+		Milestones: []int{1000000},
+		State:      state,
+		Logger:     zaptest.NewLogger(t),
+		Webhooks: []*eci.Webhook{{
+			URL:        server.URL,
+			Template:   template.Must(eci.ParseWebhookTemplate(`{{ .Type }} {{ .Threshold }}`)),
+			HTTPClient: http.DefaultClient,
+		}},
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	go engine.Run(ctx)
+
+	rn := *MustParseRegistrationNumber("ECI(2024)000007")
+	require.NoError(t, engine.Write(t.Context(), rn, &eci.ProgressResponse{SOSReport: eci.SOSReport{TotalSignatures: 1000000}}))
+
+	assert.Eventually(t, func() bool { return state.Fired("ECI(2024)000007/milestone//1000000") },
+		time.Second, 10*time.Millisecond)
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	assert.Equal(t, []string{"milestone 1000000"}, recorder.payloads)
+}
+
+func TestLoadState_Corrupt(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+
+	_, err := eci.LoadState(path)
+	require.ErrorContains(t, err, "decode state")
+}
@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Health states reported by /healthz.
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded"
+)
+
+// HealthStatus is the response of /healthz.
+type HealthStatus struct {
+	Status      string                   `json:"status"`
+	Initiatives map[string]BreakerStatus `json:"initiatives"`
+}
+
+// Health reports the circuit breaker state of every initiative. The exporter is degraded when a breaker is not
+// closed; it still responds with 200 because restarting the exporter does not help against an ECI outage.
+func (a *Application) Health(w http.ResponseWriter, _ *http.Request) {
+	status := HealthStatus{Status: HealthOK, Initiatives: a.Breakers.Statuses()}
+
+	for _, s := range status.Initiatives {
+		if s.State != BreakerClosed {
+			status.Status = HealthDegraded
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	err := json.NewEncoder(w).Encode(status)
+	if err != nil {
+		a.Logger.Debug("Cannot write health status")
+	}
+}
@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// version is the version of the exporter, set at build time with -ldflags "-X main.version=…".
+var version = "dev"
+
+// UserAgent is the User-Agent header sent to the ECI API and the sinks.
+func UserAgent() string {
+	return ServiceName + "/" + version + " (+https://github.com/tvanriel/eci-prometheus-exporter)"
+}
+
+// ErrInvalidCABundle is returned when the CA bundle contains no certificates.
+var ErrInvalidCABundle = errors.New("no certificates in CA bundle")
+
+// HTTPClientConfig configures the HTTP client that calls the ECI API.
+type HTTPClientConfig struct {
+	DialTimeout           time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+
+	// KeepAlive is the period of the TCP keep-alive probes, negative to disable them.
+	KeepAlive time.Duration
+	// DisableKeepAlives opens a new connection for every request instead of reusing idle connections.
+	DisableKeepAlives   bool
+	IdleConnTimeout     time.Duration
+	MaxIdleConnsPerHost int
+
+	// ProxyURL is the HTTP(S) proxy, by default the proxy of the HTTPS_PROXY and NO_PROXY environment variables.
+	ProxyURL string
+
+	// CAFile contains PEM certificates that are trusted in addition to the system roots.
+	CAFile string
+	// CertFile and KeyFile are the client certificate for mutual TLS.
+	CertFile string
+	KeyFile  string
+
+	UserAgent string
+}
+
+// NewHTTPClient creates a traced HTTP client from the configuration.
+func NewHTTPClient(cfg HTTPClientConfig) (*http.Client, error) {
+	tlsConfig, err := cfg.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := http.ProxyFromEnvironment
+
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("parse proxy url: %w", err)
+		}
+
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	dialer := &net.Dialer{Timeout: cfg.DialTimeout, KeepAlive: cfg.KeepAlive}
+
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   cfg.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: cfg.ResponseHeaderTimeout,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		ForceAttemptHTTP2:     true,
+		DisableKeepAlives:     cfg.DisableKeepAlives,
+	}
+
+	return &http.Client{
+		Transport: otelhttp.NewTransport(&userAgentTransport{userAgent: cfg.UserAgent, next: transport}),
+	}, nil
+}
+
+func (cfg HTTPClientConfig) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA bundle: %w", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCABundle, cfg.CAFile)
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// userAgentTransport sets the User-Agent of requests that do not have one.
+type userAgentTransport struct {
+	userAgent string
+	next      http.RoundTripper
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.userAgent == "" || req.Header.Get("User-Agent") != "" {
+		return t.next.RoundTrip(req) //nolint:wrapcheck // transparent wrapper.
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.userAgent)
+
+	return t.next.RoundTrip(req) //nolint:wrapcheck // transparent wrapper.
+}
@@ -0,0 +1,269 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	eci "github.com/tvanriel/eci-prometheus-exporter"
+)
+
+// writePEM writes the blocks to a file in a temporary directory and returns its path.
+func writePEM(t *testing.T, name string, blocks ...*pem.Block) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+
+	f, err := os.Create(path)
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	for _, b := range blocks {
+		require.NoError(t, pem.Encode(f, b))
+	}
+
+	return path
+}
+
+// clientCertificate creates a self-signed client certificate and returns the paths of the certificate and key.
+func clientCertificate(t *testing.T) (string, string) {
+	t.Helper()
+
+	return selfSignedCertificate(t, t.TempDir(), "client")
+}
+
+// selfSignedCertificate writes a self-signed certificate for 127.0.0.1 that can be used by servers and clients to
+// name.crt and name.key in the directory and returns their paths.
+func selfSignedCertificate(t *testing.T, dir, name string) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	return certFile, keyFile
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	t.Parallel()
+
+	var userAgent string
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.UserAgent()
+
+		if len(r.TLS.PeerCertificates) == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequestClientCert, MinVersion: tls.VersionTLS12}
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := writePEM(t, "ca.crt", &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	certFile, keyFile := clientCertificate(t)
+
+	tests := map[string]struct {
+		cfg        eci.HTTPClientConfig
+		wantStatus int
+		wantAgent  string
+		err        assert.ErrorAssertionFunc
+	}{
+		"untrusted server": {
+			cfg: eci.HTTPClientConfig{},
+			err: errContains("certificate"),
+		},
+		"custom CA": {
+			cfg:        eci.HTTPClientConfig{CAFile: caFile, UserAgent: "test/1.0"},
+			wantStatus: http.StatusUnauthorized,
+			wantAgent:  "test/1.0",
+			err:        assert.NoError,
+		},
+		"client certificate": {
+			cfg:        eci.HTTPClientConfig{CAFile: caFile, CertFile: certFile, KeyFile: keyFile, UserAgent: eci.UserAgent()},
+			wantStatus: http.StatusNoContent,
+			wantAgent:  eci.UserAgent(),
+			err:        assert.NoError,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, err := eci.NewHTTPClient(tt.cfg)
+			require.NoError(t, err)
+
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL, nil)
+			require.NoError(t, err)
+
+			resp, err := client.Do(req)
+			if !tt.err(t, err) || err != nil {
+				return
+			}
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			assert.Equal(t, tt.wantAgent, userAgent)
+		})
+	}
+}
+
+func TestNewHTTPClient_Errors(t *testing.T) {
+	t.Parallel()
+
+	empty := writePEM(t, "empty.crt")
+
+	tests := map[string]struct {
+		cfg eci.HTTPClientConfig
+		err assert.ErrorAssertionFunc
+	}{
+		"missing CA file": {
+			cfg: eci.HTTPClientConfig{CAFile: filepath.Join(t.TempDir(), "missing.crt")},
+			err: errContains("read CA bundle"),
+		},
+		"empty CA file": {
+			cfg: eci.HTTPClientConfig{CAFile: empty},
+			err: func(t assert.TestingT, err error, _ ...any) bool {
+				return assert.ErrorIs(t, err, eci.ErrInvalidCABundle)
+			},
+		},
+		"key without certificate": {
+			cfg: eci.HTTPClientConfig{KeyFile: empty},
+			err: errContains("load client certificate"),
+		},
+		"invalid proxy": {
+			cfg: eci.HTTPClientConfig{ProxyURL: "http://[::1"},
+			err: errContains("parse proxy url"),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := eci.NewHTTPClient(tt.cfg)
+			tt.err(t, err)
+		})
+	}
+}
+
+func TestNewHTTPClient_Proxy(t *testing.T) {
+	t.Parallel()
+
+	var proxied string
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer proxy.Close()
+
+	client, err := eci.NewHTTPClient(eci.HTTPClientConfig{ProxyURL: proxy.URL})
+	require.NoError(t, err)
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://register.eci.example/api", nil)
+	require.NoError(t, err)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "http://register.eci.example/api", proxied)
+}
+
+func TestNewHTTPClient_KeepAlives(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		cfg       eci.HTTPClientConfig
+		wantConns int32
+	}{
+		"reuses connections": {
+			cfg:       eci.HTTPClientConfig{KeepAlive: 30 * time.Second},
+			wantConns: 1,
+		},
+		"without TCP keep-alive probes": {
+			cfg:       eci.HTTPClientConfig{KeepAlive: -1},
+			wantConns: 1,
+		},
+		"disabled keep-alives": {
+			cfg:       eci.HTTPClientConfig{KeepAlive: 30 * time.Second, DisableKeepAlives: true},
+			wantConns: 3,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var conns atomic.Int32
+
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+				if state == http.StateNew {
+					conns.Add(1)
+				}
+			}
+			server.Start()
+			defer server.Close()
+
+			client, err := eci.NewHTTPClient(tt.cfg)
+			require.NoError(t, err)
+
+			for range 3 {
+				req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL, nil)
+				require.NoError(t, err)
+
+				resp, err := client.Do(req)
+				require.NoError(t, err)
+				require.NoError(t, resp.Body.Close())
+			}
+
+			assert.Equal(t, tt.wantConns, conns.Load())
+		})
+	}
+}
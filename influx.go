@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// InfluxWriter writes samples in the InfluxDB line protocol over HTTP.
+// The URL is the full write endpoint, e.g. http://influxdb:8086/api/v2/write?org=eci&bucket=eci&precision=ns
+// or the /write endpoint of VictoriaMetrics.
+type InfluxWriter struct {
+	URL        string
+	Token      string
+	HTTPClient *http.Client
+}
+
+var (
+	influxMeasurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	influxTagEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+)
+
+// LineProtocol encodes the samples in the InfluxDB line protocol with nanosecond precision.
+func LineProtocol(samples []Sample) []byte {
+	var buf bytes.Buffer
+
+	for _, s := range samples {
+		buf.WriteString(influxMeasurementEscaper.Replace(s.Name))
+
+		keys := make([]string, 0, len(s.Labels))
+		for k := range s.Labels {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			buf.WriteByte(',')
+			buf.WriteString(influxTagEscaper.Replace(k))
+			buf.WriteByte('=')
+			buf.WriteString(influxTagEscaper.Replace(s.Labels[k]))
+		}
+
+		buf.WriteString(" value=")
+		buf.WriteString(strconv.FormatFloat(s.Value, 'f', -1, 64))
+		buf.WriteByte(' ')
+		buf.WriteString(strconv.FormatInt(s.Time.UnixNano(), 10))
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes()
+}
+
+// WriteSamples implements [SampleWriter].
+func (w *InfluxWriter) WriteSamples(ctx context.Context, samples []Sample) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(LineProtocol(samples)))
+	if err != nil {
+		return fmt.Errorf("make request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+
+	if w.Token != "" {
+		req.Header.Set("Authorization", "Token "+w.Token)
+	}
+
+	return doSinkRequest(w.HTTPClient, req)
+}
+
+// ErrSinkResponse is returned when a time-series database rejects a write.
+var ErrSinkResponse = errors.New("unexpected sink response")
+
+// doSinkRequest performs a write request and treats every non-2xx response as an error.
+func doSinkRequest(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("doing request: %w", err)
+	}
+
+	defer resp.Body.Close() //nolint:errcheck // don't really care.
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%w: %d", ErrSinkResponse, resp.StatusCode)
+	}
+
+	return nil
+}
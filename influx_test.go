@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	eci "github.com/tvanriel/eci-prometheus-exporter"
+)
+
+func TestLineProtocol(t *testing.T) {
+	t.Parallel()
+
+	samples := []eci.Sample{{
+		Name:   "eci_signatures",
+		Labels: map[string]string{"initiative_id": "ECI(2024)000007", "country_code": "NL", "note": "a b,c=d"},
+		Value:  75811,
+		Time:   time.Unix(1, 5),
+	}}
+
+	assert.Equal(t,
+		"eci_signatures,country_code=NL,initiative_id=ECI(2024)000007,note=a\\ b\\,c\\=d value=75811 1000000005\n",
+		string(eci.LineProtocol(samples)),
+	)
+}
+
+func TestInfluxWriter_WriteSamples(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		status  int
+		wantErr assert.ErrorAssertionFunc
+	}{
+		"accepted": {
+			status:  http.StatusNoContent,
+			wantErr: assert.NoError,
+		},
+		"rejected": {
+			status:  http.StatusBadRequest,
+			wantErr: errContains("unexpected sink response: 400"),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				auth string
+				body []byte
+			)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				auth = r.Header.Get("Authorization")
+				body, _ = io.ReadAll(r.Body)
+
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			writer := &eci.InfluxWriter{URL: server.URL, Token: "secret", HTTPClient: http.DefaultClient}
+			samples := eci.ReportSamples(*MustParseRegistrationNumber("ECI(2024)000007"), testReport(t), time.Now())
+
+			tt.wantErr(t, writer.WriteSamples(t.Context(), samples))
+			assert.Equal(t, "Token secret", auth)
+			require.Equal(t, eci.LineProtocol(samples), body)
+		})
+	}
+}
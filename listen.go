@@ -0,0 +1,247 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Prefixes of the listen addresses that are not TCP addresses.
+const (
+	ListenUnixPrefix = "unix:"
+	ListenSystemd    = "systemd"
+)
+
+// States sent to systemd by [SdNotify].
+const (
+	SdNotifyReady    = "READY=1"
+	SdNotifyStopping = "STOPPING=1"
+)
+
+// listenFDsStart is the first file descriptor passed by systemd socket activation.
+const listenFDsStart = 3
+
+// Errors of the listen addresses.
+var (
+	ErrNoListenAddress  = errors.New("no listen address")
+	ErrNoSystemdSockets = errors.New("no matching sockets passed by systemd")
+)
+
+// ParseListenAddresses splits the comma-separated listen addresses.
+func ParseListenAddresses(list string) []string {
+	var addresses []string
+
+	for _, address := range strings.Split(list, ",") {
+		address = strings.TrimSpace(address)
+		if address != "" {
+			addresses = append(addresses, address)
+		}
+	}
+
+	return addresses
+}
+
+// Listen opens a listener for every address in the comma-separated Address: host:port for TCP, unix:/path for a
+// Unix socket with UnixSocketMode permissions, systemd for every socket passed by systemd socket activation, or
+// systemd:name for the sockets with FileDescriptorName=name.
+func (a *Application) Listen() ([]net.Listener, error) {
+	var (
+		listeners []net.Listener
+		systemd   map[string][]net.Listener
+	)
+
+	fail := func(err error) ([]net.Listener, error) {
+		for _, l := range listeners {
+			_ = l.Close()
+		}
+
+		return nil, err
+	}
+
+	for _, address := range ParseListenAddresses(a.Address) {
+		switch {
+		case strings.HasPrefix(address, ListenUnixPrefix):
+			l, err := listenUnix(strings.TrimPrefix(address, ListenUnixPrefix), a.UnixSocketMode)
+			if err != nil {
+				return fail(err)
+			}
+
+			listeners = append(listeners, l)
+		case address == ListenSystemd || strings.HasPrefix(address, ListenSystemd+":"):
+			if systemd == nil {
+				var err error
+
+				systemd, err = SystemdListeners()
+				if err != nil {
+					return fail(err)
+				}
+			}
+
+			name, _ := strings.CutPrefix(strings.TrimPrefix(address, ListenSystemd), ":")
+
+			matched := 0
+
+			for fdName, ls := range systemd {
+				if name == "" || name == fdName {
+					listeners = append(listeners, ls...)
+					matched += len(ls)
+				}
+			}
+
+			if matched == 0 {
+				return fail(fmt.Errorf("%w: %s", ErrNoSystemdSockets, address))
+			}
+		default:
+			l, err := net.Listen("tcp", address)
+			if err != nil {
+				return fail(fmt.Errorf("listen on %s: %w", address, err))
+			}
+
+			listeners = append(listeners, l)
+		}
+	}
+
+	if len(listeners) == 0 {
+		return nil, ErrNoListenAddress
+	}
+
+	return listeners, nil
+}
+
+// listenUnix listens on a Unix socket, replacing a stale socket left behind by a previous run.
+func listenUnix(path string, mode fs.FileMode) (net.Listener, error) {
+	info, err := os.Lstat(path)
+	if err == nil && info.Mode().Type() == fs.ModeSocket {
+		err = os.Remove(path)
+		if err != nil {
+			return nil, fmt.Errorf("remove stale socket: %w", err)
+		}
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("listen on %s: %w", path, err)
+	}
+
+	err = os.Chmod(path, mode)
+	if err != nil {
+		_ = l.Close()
+
+		return nil, fmt.Errorf("set socket permissions: %w", err)
+	}
+
+	return l, nil
+}
+
+// SystemdListeners returns the sockets passed by systemd socket activation by their FileDescriptorName. The
+// environment variables are cleared, so the sockets are not passed on to child processes.
+func SystemdListeners() (map[string][]net.Listener, error) {
+	defer func() {
+		_ = os.Unsetenv("LISTEN_PID")
+		_ = os.Unsetenv("LISTEN_FDS")
+		_ = os.Unsetenv("LISTEN_FDNAMES")
+	}()
+
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, ErrNoSystemdSockets
+	}
+
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count <= 0 {
+		return nil, ErrNoSystemdSockets
+	}
+
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	listeners := map[string][]net.Listener{}
+
+	for i := range count {
+		fd := listenFDsStart + i
+
+		name := "unknown"
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+
+		// FileListener duplicates the descriptor with close-on-exec set, so the original can be closed.
+		f := os.NewFile(uintptr(fd), name)
+
+		l, err := net.FileListener(f)
+		_ = f.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("use systemd socket %d: %w", fd, err)
+		}
+
+		listeners[name] = append(listeners[name], l)
+	}
+
+	return listeners, nil
+}
+
+// ServeListeners serves the HTTP server on every listener until one of them fails.
+func (a *Application) ServeListeners(listeners []net.Listener) error {
+	errs := make(chan error, len(listeners))
+
+	// Serve fills in TLSConfig for HTTP/2, so it must be checked before the first listener is served.
+	useTLS := a.HTTPServer.TLSConfig != nil
+
+	for _, l := range listeners {
+		a.Logger.Info("Serving Prometheus metrics",
+			zap.String("endpoint", "/metrics"),
+			zap.String("address", l.Addr().Network()+":"+l.Addr().String()),
+		)
+
+		go func() {
+			if useTLS {
+				errs <- a.HTTPServer.ServeTLS(l, "", "")
+			} else {
+				errs <- a.HTTPServer.Serve(l)
+			}
+		}()
+	}
+
+	err := <-errs
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	a.Logger.Error("HTTP server failed", zap.Error(err))
+
+	return fmt.Errorf("serve application server: %w", err)
+}
+
+// SdNotify sends the state to the systemd service manager. It does nothing when the exporter does not run as a
+// systemd service with Type=notify.
+func SdNotify(state string) error {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+
+	if strings.HasPrefix(socket, "@") {
+		socket = "\x00" + socket[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return fmt.Errorf("connect to systemd: %w", err)
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(state))
+	if err != nil {
+		return fmt.Errorf("notify systemd: %w", err)
+	}
+
+	return nil
+}
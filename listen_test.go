@@ -0,0 +1,299 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main_test
+
+import (
+	"context"
+	"flag"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	eci "github.com/tvanriel/eci-prometheus-exporter"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestParseListenAddresses(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		list string
+		want []string
+	}{
+		"empty":  {list: "", want: nil},
+		"single": {list: ":8080", want: []string{":8080"}},
+		"mixed": {
+			list: ":8080, unix:/run/eci/exporter.sock,systemd:metrics,",
+			want: []string{":8080", "unix:/run/eci/exporter.sock", "systemd:metrics"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, eci.ParseListenAddresses(tt.list))
+		})
+	}
+}
+
+func TestApplication_Listen(t *testing.T) {
+	t.Parallel()
+
+	socket := filepath.Join(t.TempDir(), "exporter.sock")
+
+	// Leave a stale socket behind, as after a crash.
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: socket, Net: "unix"})
+	require.NoError(t, err)
+	stale.SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	app := eci.NewApplication(zaptest.NewLogger(t), "", nil, "127.0.0.1:0,unix:"+socket, http.DefaultClient)
+	app.UnixSocketMode = 0o600
+
+	listeners, err := app.Listen()
+	require.NoError(t, err)
+	require.Len(t, listeners, 2)
+
+	info, err := os.Stat(socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	go func() { _ = app.ServeListeners(listeners) }()
+
+	defer app.HTTPServer.Close()
+
+	unixClient := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+
+	for client, url := range map[*http.Client]string{
+		http.DefaultClient: "http://" + listeners[0].Addr().String() + "/metrics",
+		unixClient:         "http://unix/metrics",
+	} {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, url, nil)
+		require.NoError(t, err)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+
+		_ = resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode, url)
+	}
+}
+
+//nolint:paralleltest // changes the environment.
+func TestApplication_ListenErrors(t *testing.T) {
+	t.Setenv("LISTEN_PID", "")
+
+	// Only stale sockets are replaced, never other files.
+	notSocket := filepath.Join(t.TempDir(), "exporter.sock")
+	require.NoError(t, os.WriteFile(notSocket, nil, 0o600))
+
+	tests := map[string]struct {
+		address string
+		err     assert.ErrorAssertionFunc
+	}{
+		"no address": {
+			address: " , ",
+			err:     errIs(eci.ErrNoListenAddress),
+		},
+		"missing socket directory": {
+			address: "unix:" + filepath.Join(t.TempDir(), "missing", "exporter.sock"),
+			err:     errContains("listen on"),
+		},
+		"not a socket": {
+			address: "unix:" + notSocket,
+			err:     errContains("listen on"),
+		},
+		"not started by systemd": {
+			address: "127.0.0.1:0,systemd",
+			err:     errIs(eci.ErrNoSystemdSockets),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			app := eci.NewApplication(zaptest.NewLogger(t), "", nil, tt.address, http.DefaultClient)
+
+			_, err := app.Listen()
+			tt.err(t, err)
+		})
+	}
+}
+
+// systemdChildEnv names the case of the re-executed test binary that plays the socket-activated exporter.
+const systemdChildEnv = "ECI_TEST_SYSTEMD_CHILD"
+
+// systemdChild runs a case of [TestSystemdListeners] in the re-executed test binary, where the passed sockets start
+// at the file descriptor systemd uses.
+func systemdChild(t *testing.T, name string) {
+	t.Helper()
+
+	// systemd sets LISTEN_PID to the PID of the started process, which the parent cannot know in advance.
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+
+	switch name {
+	case "named sockets":
+		app := eci.NewApplication(zaptest.NewLogger(t), "", nil, "systemd:metrics,systemd:unknown", http.DefaultClient)
+
+		listeners, err := app.Listen()
+		require.NoError(t, err)
+		require.Len(t, listeners, 2)
+		assert.Empty(t, os.Getenv("LISTEN_PID"), "the environment is cleared")
+		assert.Empty(t, os.Getenv("LISTEN_FDS"), "the environment is cleared")
+		assert.Empty(t, os.Getenv("LISTEN_FDNAMES"), "the environment is cleared")
+
+		for i, l := range listeners {
+			conn, err := l.Accept()
+			require.NoError(t, err)
+
+			_, err = conn.Write([]byte([]string{"metrics", "unknown"}[i]))
+			require.NoError(t, err)
+			require.NoError(t, conn.Close())
+		}
+	case "not a socket":
+		_, err := eci.SystemdListeners()
+		require.ErrorContains(t, err, "use systemd socket 3")
+	default:
+		t.Fatalf("unknown case %q", name)
+	}
+}
+
+func TestSystemdListeners(t *testing.T) {
+	if name := os.Getenv(systemdChildEnv); name != "" {
+		systemdChild(t, name)
+
+		return
+	}
+
+	t.Parallel()
+
+	tests := map[string]struct {
+		sockets int
+		names   string
+		want    []string
+	}{
+		// The second socket has no name.
+		"named sockets": {sockets: 2, names: "metrics", want: []string{"metrics", "unknown"}},
+		"not a socket":  {names: "metrics"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				files     []*os.File
+				addresses []string
+			)
+
+			for range tt.sockets {
+				l, err := net.ListenTCP("tcp", &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)})
+				require.NoError(t, err)
+
+				f, err := l.File()
+				require.NoError(t, err)
+				require.NoError(t, l.Close())
+
+				files = append(files, f)
+				addresses = append(addresses, l.Addr().String())
+			}
+
+			if tt.sockets == 0 {
+				r, w, err := os.Pipe()
+				require.NoError(t, err)
+				require.NoError(t, w.Close())
+
+				files = append(files, r)
+			}
+
+			args := []string{"-test.run=^TestSystemdListeners$"}
+
+			// Write the coverage of the child next to the coverage of this test binary, so the two are merged.
+			if dir := flag.Lookup("test.gocoverdir"); dir != nil && dir.Value.String() != "" {
+				args = append(args, "-test.gocoverdir="+dir.Value.String())
+			}
+
+			cmd := exec.CommandContext(t.Context(), os.Args[0], args...)
+			cmd.Env = append(os.Environ(), systemdChildEnv+"="+name,
+				"LISTEN_FDS="+strconv.Itoa(len(files)), "LISTEN_FDNAMES="+tt.names)
+			cmd.ExtraFiles = files
+			cmd.Stdout = os.Stdout
+			cmd.Stderr = os.Stderr
+			require.NoError(t, cmd.Start())
+
+			for _, f := range files {
+				require.NoError(t, f.Close())
+			}
+
+			var got []string
+
+			for _, address := range addresses {
+				conn, err := net.Dial("tcp", address)
+				require.NoError(t, err)
+
+				b, err := io.ReadAll(conn)
+				require.NoError(t, err)
+				require.NoError(t, conn.Close())
+
+				got = append(got, string(b))
+			}
+
+			require.NoError(t, cmd.Wait())
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+//nolint:paralleltest // changes the environment.
+func TestSystemdListeners_Errors(t *testing.T) {
+	tests := map[string]struct {
+		pid string
+		fds string
+	}{
+		"no pid":        {pid: "", fds: "1"},
+		"other process": {pid: strconv.Itoa(os.Getpid() + 1), fds: "1"},
+		"invalid count": {pid: strconv.Itoa(os.Getpid()), fds: "many"},
+		"no sockets":    {pid: strconv.Itoa(os.Getpid()), fds: "0"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("LISTEN_PID", tt.pid)
+			t.Setenv("LISTEN_FDS", tt.fds)
+			t.Setenv("LISTEN_FDNAMES", "metrics")
+
+			_, err := eci.SystemdListeners()
+			require.ErrorIs(t, err, eci.ErrNoSystemdSockets)
+			assert.Empty(t, os.Getenv("LISTEN_FDS"), "the environment is cleared")
+		})
+	}
+}
+
+func TestSdNotify(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "notify.sock")
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	require.NoError(t, err)
+
+	defer conn.Close()
+
+	t.Setenv("NOTIFY_SOCKET", "")
+	require.NoError(t, eci.SdNotify(eci.SdNotifyReady), "no-op outside of systemd")
+
+	t.Setenv("NOTIFY_SOCKET", socket)
+	require.NoError(t, eci.SdNotify(eci.SdNotifyReady))
+
+	buf := make([]byte, 64)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+
+	assert.Equal(t, "READY=1", string(buf[:n]))
+}
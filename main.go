@@ -20,70 +20,611 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"net/http"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"golang.org/x/time/rate"
 )
 
-func main() {
-	initiativeList := flag.String("initiatives", "", "Comma-separated list of initiative IDs (e.g. 043,045,098)")
-	address := flag.String("listen-address", ":8080", "Address to expose Prometheus metrics")
-	interval := flag.Duration("interval", defaultInterval, "Polling interval for API updates")
-	apiURL := flag.String("api-url", "https://register.eci.ec.europa.eu", "The URL to the ECI API")
+// options are the command line flags of the exporter.
+type options struct {
+	initiatives       RegistrationNumbers
+	address           string
+	interval          time.Duration
+	apiURL            string
+	textfileDirectory string
+	probeCacheTTL     time.Duration
+	concurrency       int
+	rateLimit         float64
+	adaptive          bool
+	breakerFailures   int
+	breakerCooldown   time.Duration
+	adaptiveWindow    time.Duration
+	maxStaleness      time.Duration
+	httpClient        HTTPClientConfig
+	webConfigFile     string
+	adminAPI          bool
+	unixSocketMode    string
+	validate          string
+
+	once           bool
+	pushgatewayURL string
+	pushJob        string
+	pushGrouping   string
+
+	otlpEndpoint string
+	otlpProtocol string
+	otlpInterval time.Duration
+	otlpTraces   bool
+
+	sinkInfluxURL      string
+	sinkInfluxToken    string
+	sinkRemoteWriteURL string
+	sinkNDJSONFile     string
+	sinkBatch          BatchConfig
+
+	stateFile       string
+	webhookURLs     stringsFlag
+	webhookTemplate string
+	webhookRetries  int
+	milestones      string
+	notifyDecreases bool
+}
+
+// stringsFlag is a flag that can be given multiple times.
+type stringsFlag []string
+
+// String implements [flag.Value].
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+// Set implements [flag.Value].
+func (s *stringsFlag) Set(v string) error {
+	*s = append(*s, v)
+
+	return nil
+}
+
+func parseOptions() *options {
+	o := &options{}
+
+	flag.Var(&o.initiatives, "initiatives", "Comma-separated list of initiative IDs, can be repeated (e.g. ECI(2024)000007,2024/000008)")
+	flag.StringVar(&o.address, "listen-address", ":8080",
+		"Comma-separated addresses to expose Prometheus metrics on (host:port, unix:/path, systemd or systemd:name), empty to disable")
+	flag.StringVar(&o.unixSocketMode, "unix-socket-mode", "0660", "Permissions of the unix: listen sockets")
+	flag.StringVar(&o.webConfigFile, "web-config-file", "", "File with the TLS and authentication configuration of the HTTP endpoints")
+	flag.DurationVar(&o.interval, "interval", defaultInterval, "Polling interval for API updates")
+	flag.IntVar(&o.concurrency, "concurrency", defaultConcurrency, "Maximum number of concurrent requests to the ECI API")
+	flag.IntVar(&o.breakerFailures, "breaker-failures", defaultBreakerFailures,
+		"Consecutive failed polls after which an initiative is not polled for -breaker-cooldown, 0 to disable")
+	flag.DurationVar(&o.breakerCooldown, "breaker-cooldown", defaultBreakerCooldown, "Time before a failing initiative is polled again")
+	flag.BoolVar(&o.adaptive, "adaptive", false, "Poll every -interval only around the learned daily ECI update")
+	flag.DurationVar(&o.adaptiveWindow, "adaptive-window", defaultAdaptiveWindow, "Width of the polling window around the expected update")
+	flag.DurationVar(&o.maxStaleness, "max-staleness", defaultMaxStaleness, "Maximum time between polls in adaptive mode")
+	flag.Float64Var(&o.rateLimit, "rate-limit", defaultRateLimit, "Maximum requests per second to the ECI API, 0 for no limit")
+	flag.DurationVar(&o.probeCacheTTL, "probe-cache-ttl", defaultProbeCacheTTL, "How long /probe reuses a fetched report")
+	flag.StringVar(&o.apiURL, "api-url", "https://register.eci.ec.europa.eu", "The URL to the ECI API")
+	flag.StringVar(&o.validate, "validate-initiatives", ValidateWarn,
+		"What to do with initiatives that do not exist at startup: warn to report them as invalid, fail to exit, or off")
+
+	flag.DurationVar(&o.httpClient.DialTimeout, "http-dial-timeout", defaultDialTimeout, "Timeout for connecting to the ECI API")
+	flag.DurationVar(&o.httpClient.TLSHandshakeTimeout, "http-tls-handshake-timeout", defaultTLSHandshakeTimeout,
+		"Timeout for the TLS handshake with the ECI API")
+	flag.DurationVar(&o.httpClient.ResponseHeaderTimeout, "http-response-header-timeout", defaultResponseHeaderTimeout,
+		"Timeout for the response headers of the ECI API")
+	flag.DurationVar(&o.httpClient.KeepAlive, "http-keep-alive", defaultKeepAlive, "TCP keep-alive period, negative to disable keep-alive probes")
+	flag.BoolVar(&o.httpClient.DisableKeepAlives, "http-disable-keep-alives", false,
+		"Open a new connection for every request instead of reusing idle connections")
+	flag.DurationVar(&o.httpClient.IdleConnTimeout, "http-idle-conn-timeout", defaultIdleConnTimeout,
+		"How long idle connections are kept open")
+	flag.IntVar(&o.httpClient.MaxIdleConnsPerHost, "http-max-idle-conns-per-host", defaultMaxIdleConnsPerHost,
+		"Maximum number of idle connections per host")
+	flag.StringVar(&o.httpClient.ProxyURL, "http-proxy", "", "HTTP(S) proxy URL, by default taken from HTTPS_PROXY and NO_PROXY")
+	flag.StringVar(&o.httpClient.CAFile, "http-ca-file", "", "PEM file with CA certificates to trust in addition to the system roots")
+	flag.StringVar(&o.httpClient.CertFile, "http-cert-file", "", "PEM client certificate for mutual TLS")
+	flag.StringVar(&o.httpClient.KeyFile, "http-key-file", "", "PEM private key of the -http-cert-file")
+	flag.StringVar(&o.httpClient.UserAgent, "http-user-agent", UserAgent(), "User-Agent sent with outgoing requests")
+	flag.StringVar(
+		&o.textfileDirectory,
+		"textfile-directory",
+		"",
+		"Write metrics to "+TextfileName+" in this directory instead of serving them over HTTP",
+	)
+
+	flag.BoolVar(&o.once, "once", false, "Fetch every initiative once, push the results and exit non-zero on failure")
+	flag.StringVar(&o.pushgatewayURL, "pushgateway-url", "", "Pushgateway URL to push the metrics to in -once mode")
+	flag.StringVar(&o.pushJob, "push-job", "eci_exporter", "Job name used when pushing to the Pushgateway")
+	flag.StringVar(&o.pushGrouping, "push-grouping", "", "Comma-separated key=value grouping labels for the Pushgateway")
+
+	flag.StringVar(
+		&o.otlpEndpoint,
+		"otlp-endpoint",
+		"",
+		"OpenTelemetry collector URL to push metrics to (e.g. http://localhost:4318)",
+	)
+	flag.StringVar(&o.otlpProtocol, "otlp-protocol", OTLPProtocolHTTP, "OTLP protocol, http or grpc")
+	flag.DurationVar(&o.otlpInterval, "otlp-interval", time.Minute, "Interval between OTLP metric exports")
+	flag.BoolVar(&o.otlpTraces, "otlp-traces", false, "Export spans around the ECI API calls to the -otlp-endpoint")
+
+	flag.StringVar(&o.sinkInfluxURL, "sink-influx-url", "", "InfluxDB line protocol write URL")
+	flag.StringVar(&o.sinkInfluxToken, "sink-influx-token", "", "Token for the InfluxDB write URL")
+	flag.StringVar(&o.sinkRemoteWriteURL, "sink-remote-write-url", "", "Prometheus remote-write URL")
+	flag.StringVar(&o.sinkNDJSONFile, "sink-ndjson-file", "", "File to append newline-delimited JSON samples to")
+	flag.IntVar(&o.sinkBatch.BatchSize, "sink-batch-size", defaultSinkBatchSize, "Number of samples per sink write")
+	flag.DurationVar(&o.sinkBatch.FlushInterval, "sink-flush-interval", defaultSinkFlushInterval, "Maximum time samples are buffered")
+	flag.IntVar(&o.sinkBatch.Retries, "sink-retries", defaultSinkRetries, "Number of retries for failed sink writes")
+	flag.DurationVar(&o.sinkBatch.RetryBackoff, "sink-retry-backoff", time.Second, "Backoff between sink write retries")
+
+	flag.StringVar(&o.stateFile, "state-file", "",
+		"File to persist delivered events and initiatives changed through the admin API in, so they survive a restart")
+	flag.BoolVar(&o.adminAPI, "admin-api", false, "Serve /api/v1/admin/initiatives, requires admin credentials in -web-config-file")
+	flag.Var(&o.webhookURLs, "webhook-url", "URL to post milestone and threshold events to, can be repeated")
+	flag.StringVar(&o.webhookTemplate, "webhook-template", "", "File with the Go template for the webhook payload")
+	flag.IntVar(&o.webhookRetries, "webhook-retries", defaultSinkRetries, "Number of retries for failed webhook deliveries")
+	flag.BoolVar(&o.notifyDecreases, "notify-decreases", false, "Send an event to the webhooks when a country total decreases")
+	flag.StringVar(&o.milestones, "milestones", "100000,500000,1000000", "Comma-separated total signature milestones")
+
 	flag.Parse()
 
+	return o
+}
+
+func main() {
+	if len(os.Args) > 1 {
+		subcommands := map[string]func([]string, io.Writer) error{
+			"rules":     runRules,
+			"dashboard": runDashboard,
+		}
+
+		if run, ok := subcommands[os.Args[1]]; ok {
+			err := run(os.Args[2:], os.Stdout)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+
+			return
+		}
+	}
+
+	opts := parseOptions()
+
 	logger, err := zap.NewProduction()
 	if err != nil {
 		panic(fmt.Sprintf("failed to initialize logger: %v", err))
 	}
 	defer logger.Sync() //nolint:errcheck // don't care.
 
-	initiatives := strings.Split(*initiativeList, ",")
-	if len(initiatives) == 0 || (len(initiatives) == 1 && initiatives[0] == "") {
-		logger.Fatal("No initiative IDs provided. Use -initiatives flag (e.g. -initiatives=045,098)")
+	if opts.once && opts.pushgatewayURL == "" && opts.textfileDirectory == "" {
+		logger.Fatal("Cannot run once", zap.Error(ErrNoOnceOutput))
+	}
+
+	err = CheckValidatePolicy(opts.validate)
+	if err != nil {
+		logger.Fatal("Cannot validate initiatives", zap.Error(err))
+	}
+
+	registrationNumbers := []RegistrationNumber(opts.initiatives)
+
+	if len(registrationNumbers) == 0 {
+		if !probeOnly(opts) {
+			logger.Fatal("Cannot parse initiatives", zap.Error(ErrNoInitiatives))
+		}
+
+		logger.Info("No initiatives configured, only serving /probe")
+	}
+
+	state, err := LoadState(opts.stateFile)
+	if err != nil {
+		logger.Fatal("Cannot load state", zap.Error(err))
+	}
+
+	registrationNumbers, err = state.Initiatives(registrationNumbers)
+	if err != nil {
+		logger.Fatal("Cannot apply initiatives of the state", zap.Error(err))
 	}
 
 	logger.Info("Starting ECI Exporter",
-		zap.Strings("initiatives", initiatives),
-		zap.String("listen_address", *address),
-		zap.Duration("interval", *interval),
+		zap.Stringer("initiatives", &opts.initiatives),
+		zap.String("listen_address", opts.address),
+		zap.Duration("interval", opts.interval),
+		zap.String("version", version),
 	)
 
-	registrationNumbers := make([]RegistrationNumber, 0, len(initiatives))
-	for _, i := range initiatives {
-		rn, err := ParseRegistrationNumber(i)
+	httpClient, err := NewHTTPClient(opts.httpClient)
+	if err != nil {
+		logger.Fatal("Cannot create HTTP client", zap.Error(err))
+	}
+
+	a := NewApplication(logger, opts.apiURL, registrationNumbers, opts.address, httpClient)
+	a.Prober.CacheTTL = opts.probeCacheTTL
+
+	socketMode, err := strconv.ParseUint(opts.unixSocketMode, 8, 32)
+	if err != nil {
+		logger.Fatal("Cannot parse unix socket mode", zap.Error(err))
+	}
+
+	a.UnixSocketMode = os.FileMode(socketMode)
+
+	if opts.adminAPI {
+		a.Mux.Handle("/api/v1/admin/", NewAdminAPI(a, state))
+	}
+
+	err = setupWebConfig(a, opts)
+	if err != nil {
+		logger.Fatal("Cannot load web config", zap.Error(err))
+	}
+	a.Breakers = NewBreakers(opts.breakerFailures, opts.breakerCooldown, registrationNumbers)
+
+	limit := rate.Limit(opts.rateLimit)
+	if opts.rateLimit <= 0 {
+		limit = rate.Inf
+	}
+
+	a.Scheduler = NewScheduler(registrationNumbers, opts.interval, opts.concurrency, limit,
+		func(ctx context.Context, registrationNumber RegistrationNumber) error {
+			return a.poll(ctx, registrationNumber, opts.interval)
+		},
+	)
+
+	var registerer prometheus.Registerer = prometheus.DefaultRegisterer
+
+	if opts.textfileDirectory != "" {
+		registry := prometheus.NewRegistry()
+		registerer = registry
+		a.Textfile = NewTextfile(opts.textfileDirectory, registry)
+	}
+
+	a.MustRegisterWith(registerer)
+	a.Scheduler.MustRegisterWith(registerer)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdowns := setupOTLP(ctx, a, opts)
+	shutdowns = append(shutdowns, setupSinks(ctx, a, opts)...)
+
+	shutdowns = append(shutdowns, setupEvents(ctx, a, opts, state)...)
+
+	if opts.adaptive {
+		policy := NewAdaptivePolicy(opts.interval, opts.adaptiveWindow, opts.maxStaleness)
+		a.Scheduler.Policy = policy
+		a.Sinks = append(a.Sinks, policy)
+	}
+
+	if opts.once {
+		grouping, err := ParseGrouping(opts.pushGrouping)
+		if err != nil {
+			logger.Fatal("Cannot parse Pushgateway grouping", zap.Error(err))
+		}
+
+		validateInitiatives(ctx, a, opts, shutdowns)
+
+		err = runOnce(ctx, a, opts.interval, Pushgateway{URL: opts.pushgatewayURL, Job: opts.pushJob, Grouping: grouping})
+
+		shutdown(logger, shutdowns)
+
 		if err != nil {
-			logger.Fatal("Cannot parse registratin number", zap.String("registration_number", i))
+			logger.Fatal("One-shot run failed", zap.Error(err))
 		}
 
-		registrationNumbers = append(registrationNumbers, *rn)
+		return
 	}
 
-	a := NewApplication(
-		logger,
-		*apiURL,
-		registrationNumbers,
-		*address,
-		http.DefaultClient,
-	)
+	serveErr := make(chan error, 1)
 
-	// Start one goroutine per initiative
-	for _, id := range registrationNumbers {
-		ticker := time.NewTicker(*interval)
+	switch {
+	case a.Textfile != nil:
+		logger.Info("Writing metrics to textfile", zap.String("path", a.Textfile.Path))
+	case opts.address == "":
+		logger.Info("Metrics endpoint disabled")
+	default:
+		listeners, err := a.Listen()
+		if err != nil {
+			logger.Fatal("Cannot listen on configured address", zap.String("address", opts.address), zap.Error(err))
+		}
 
-		go a.StartPolling(id, ticker, *interval)
+		go func() { serveErr <- a.ServeListeners(listeners) }()
 	}
 
-	a.MustRegisterWith(prometheus.DefaultRegisterer)
+	notifySystemd(logger, SdNotifyReady)
 
-	err = a.Serve()
-	if err != nil {
+	// The validation waits for the rate limiter, so it runs once the exporter is up and before the first poll.
+	validateInitiatives(ctx, a, opts, shutdowns)
+
+	go a.Scheduler.Run(ctx)
+
+	select {
+	case <-ctx.Done():
+		logger.Info("Shutting down")
+	case err = <-serveErr:
+		shutdown(logger, shutdowns)
 		logger.Fatal("Run server", zap.Error(err))
 	}
+
+	notifySystemd(logger, SdNotifyStopping)
+
+	shutdowns = append(shutdowns, a.HTTPServer.Shutdown)
+	shutdown(logger, shutdowns)
+}
+
+// validateInitiatives applies the -validate-initiatives policy and exits when it fails.
+func validateInitiatives(ctx context.Context, a *Application, opts *options, shutdowns []func(context.Context) error) {
+	err := a.ApplyValidatePolicy(ctx, opts.validate, defaultValidateTimeout)
+	if err != nil {
+		shutdown(a.Logger, shutdowns)
+		a.Logger.Fatal("Cannot validate initiatives", zap.Error(err))
+	}
+}
+
+// notifySystemd sends the state to systemd when the exporter runs as a Type=notify service.
+func notifySystemd(logger *zap.Logger, state string) {
+	err := SdNotify(state)
+	if err != nil {
+		logger.Warn("Cannot notify systemd", zap.String("state", state), zap.Error(err))
+	}
+}
+
+// ErrNoOnceOutput is returned when -once has nowhere to write the results.
+var ErrNoOnceOutput = errors.New("-once requires -pushgateway-url or -textfile-directory")
+
+// ErrNoInitiatives is returned when no initiatives are configured.
+var ErrNoInitiatives = errors.New("no initiative IDs provided, use the -initiatives flag (e.g. -initiatives=ECI(2024)000007)")
+
+// probeOnly reports whether the exporter can run without initiatives, serving only /probe.
+func probeOnly(opts *options) bool {
+	return !opts.once && opts.textfileDirectory == "" && opts.address != ""
+}
+
+// setupWebConfig applies TLS and authentication from the web configuration file to every route of the exporter.
+func setupWebConfig(a *Application, opts *options) error {
+	webConfig := &WebConfig{}
+
+	if opts.webConfigFile != "" {
+		var err error
+
+		webConfig, err = LoadWebConfig(opts.webConfigFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.adminAPI && !webConfig.AuthenticatesAdmin() {
+		return ErrAdminAPIUnauthenticated
+	}
+
+	tlsConfig, err := webConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	a.HTTPServer.TLSConfig = tlsConfig
+	if opts.adminAPI {
+		a.HTTPServer.Handler = webConfig.MiddlewareWithAdminAPI(a.HTTPServer.Handler)
+	} else {
+		a.HTTPServer.Handler = webConfig.Middleware(a.HTTPServer.Handler)
+	}
+
+	return nil
+}
+
+// runRules implements the rules subcommand, which prints Prometheus rules for the configured initiatives.
+func runRules(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("rules", flag.ContinueOnError)
+
+	var initiatives RegistrationNumbers
+
+	fs.Var(&initiatives, "initiatives", "Comma-separated list of initiative IDs, can be repeated (e.g. ECI(2024)000007)")
+	format := fs.String("format", RulesFormatPlain, "Output format, plain for a rule file or crd for a PrometheusRule")
+	milestones := fs.String("milestones", "100000,500000,1000000", "Comma-separated total signature milestones")
+	staleAfter := fs.Duration("stale-after", 3*defaultInterval, "Age of the last successful poll that is considered stale")
+	name := fs.String("name", ServiceName, "Name of the PrometheusRule")
+	namespace := fs.String("namespace", "", "Namespace of the PrometheusRule")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+
+	if len(initiatives) == 0 {
+		return ErrNoInitiatives
+	}
+
+	ms, err := ParseMilestones(*milestones)
+	if err != nil {
+		return err
+	}
+
+	return WriteRules(w, RulesConfig{
+		Initiatives: initiatives,
+		Milestones:  ms,
+		StaleAfter:  *staleAfter,
+		Name:        *name,
+		Namespace:   *namespace,
+		Labels:      map[string]string{"app.kubernetes.io/name": ServiceName},
+	}, *format)
+}
+
+// runDashboard implements the dashboard subcommand, which prints the Grafana dashboard of the exporter.
+func runDashboard(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("dashboard", flag.ContinueOnError)
+
+	title := fs.String("title", "ECI Initiatives", "Title of the dashboard")
+	uid := fs.String("uid", "eci-initiatives", "UID of the dashboard")
+	logSelector := fs.String("log-selector", `{namespace="eci-prometheus-exporter"}`, "LogQL stream selector of the exporter logs")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+
+	return WriteDashboard(w, DashboardConfig{Title: *title, UID: *uid, LogSelector: *logSelector})
+}
+
+// setupOTLP configures the OTLP metric and span export and returns the functions that flush them.
+func setupOTLP(ctx context.Context, a *Application, opts *options) []func(context.Context) error {
+	if opts.otlpEndpoint == "" {
+		return nil
+	}
+
+	otlpConfig := OTLPConfig{
+		Endpoint: opts.otlpEndpoint,
+		Protocol: opts.otlpProtocol,
+		Interval: opts.otlpInterval,
+	}
+
+	registry := prometheus.NewRegistry()
+	a.MustRegisterWith(registry)
+
+	meterProvider, err := NewOTLPMeterProvider(ctx, otlpConfig, registry, a.Initiatives)
+	if err != nil {
+		a.Logger.Fatal("Cannot set up OTLP export", zap.Error(err))
+	}
+
+	a.Logger.Info("Exporting metrics via OTLP", zap.String("endpoint", opts.otlpEndpoint))
+
+	if !opts.otlpTraces {
+		return []func(context.Context) error{meterProvider.Shutdown}
+	}
+
+	tracerProvider, err := NewOTLPTracerProvider(ctx, otlpConfig, a.Initiatives)
+	if err != nil {
+		a.Logger.Fatal("Cannot set up OTLP tracing", zap.Error(err))
+	}
+
+	otel.SetTracerProvider(tracerProvider)
+
+	return []func(context.Context) error{meterProvider.Shutdown, tracerProvider.Shutdown}
+}
+
+// setupSinks adds the configured time-series sinks to the application and returns the functions that flush them.
+func setupSinks(ctx context.Context, a *Application, opts *options) []func(context.Context) error {
+	var sinks []*BatchSink
+
+	if opts.sinkInfluxURL != "" {
+		sinks = append(sinks, NewBatchSink("influx", &InfluxWriter{
+			URL:        opts.sinkInfluxURL,
+			Token:      opts.sinkInfluxToken,
+			HTTPClient: a.HTTPClient,
+		}, opts.sinkBatch, a.Logger))
+	}
+
+	if opts.sinkRemoteWriteURL != "" {
+		sinks = append(sinks, NewBatchSink("remote_write", &RemoteWriteWriter{
+			URL:        opts.sinkRemoteWriteURL,
+			HTTPClient: a.HTTPClient,
+		}, opts.sinkBatch, a.Logger))
+	}
+
+	if opts.sinkNDJSONFile != "" {
+		sinks = append(sinks, NewBatchSink("ndjson", &NDJSONWriter{Path: opts.sinkNDJSONFile}, opts.sinkBatch, a.Logger))
+	}
+
+	if len(sinks) > 0 {
+		err := opts.sinkBatch.Validate()
+		if err != nil {
+			a.Logger.Fatal("Cannot configure sinks", zap.Error(err))
+		}
+	}
+
+	flushes := make([]func(context.Context) error, 0, len(sinks))
+
+	for _, sink := range sinks {
+		go sink.Run(ctx)
+
+		a.Sinks = append(a.Sinks, sink)
+		flushes = append(flushes, sink.Flush)
+	}
+
+	return flushes
+}
+
+// setupEvents adds the event engine to the application when webhooks are configured and returns the function that
+// delivers the queued events.
+func setupEvents(ctx context.Context, a *Application, opts *options, state *State) []func(context.Context) error {
+	if len(opts.webhookURLs) == 0 {
+		return nil
+	}
+
+	milestones, err := ParseMilestones(opts.milestones)
+	if err != nil {
+		a.Logger.Fatal("Cannot parse milestones", zap.Error(err))
+	}
+
+	text := DefaultWebhookTemplate
+
+	if opts.webhookTemplate != "" {
+		content, err := os.ReadFile(opts.webhookTemplate)
+		if err != nil {
+			a.Logger.Fatal("Cannot read webhook template", zap.Error(err))
+		}
+
+		text = string(content)
+	}
+
+	tmpl, err := ParseWebhookTemplate(text)
+	if err != nil {
+		a.Logger.Fatal("Cannot parse webhook template", zap.Error(err))
+	}
+
+	engine := &EventEngine{Milestones: milestones, State: state, Logger: a.Logger}
+
+	for _, u := range opts.webhookURLs {
+		engine.Webhooks = append(engine.Webhooks, &Webhook{
+			URL:          u,
+			Template:     tmpl,
+			HTTPClient:   a.HTTPClient,
+			Retries:      opts.webhookRetries,
+			RetryBackoff: time.Second,
+		})
+	}
+
+	go engine.Run(ctx)
+
+	a.Events = engine
+	a.NotifyDecreases = opts.notifyDecreases
+	a.Sinks = append(a.Sinks, engine)
+
+	return []func(context.Context) error{engine.Flush}
+}
+
+// runOnce fetches every initiative once and pushes the results when a Pushgateway is configured.
+func runOnce(ctx context.Context, a *Application, timeout time.Duration, gateway Pushgateway) error {
+	fetchErr := a.RunOnce(ctx, timeout)
+
+	if gateway.URL != "" {
+		err := a.Push(ctx, gateway)
+		if err != nil {
+			return errors.Join(fetchErr, err)
+		}
+	}
+
+	return fetchErr
+}
+
+// shutdown flushes the pending OTLP metrics, spans, sink samples and events.
+func shutdown(logger *zap.Logger, shutdowns []func(context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultShutdownTimeout)
+	defer cancel()
+
+	for _, fn := range shutdowns {
+		err := fn(ctx)
+		if err != nil {
+			logger.Error("Cannot flush pending data", zap.Error(err))
+		}
+	}
 }
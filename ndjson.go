@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// NDJSONWriter appends samples as newline-delimited JSON to a file.
+type NDJSONWriter struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+// WriteSamples implements [SampleWriter].
+func (w *NDJSONWriter) WriteSamples(_ context.Context, samples []Sample) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	f, err := os.OpenFile(w.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644) //nolint:mnd // file mode.
+	if err != nil {
+		return fmt.Errorf("open ndjson file: %w", err)
+	}
+
+	enc := json.NewEncoder(f)
+
+	for _, s := range samples {
+		err = enc.Encode(s)
+		if err != nil {
+			_ = f.Close()
+
+			return fmt.Errorf("encode sample: %w", err)
+		}
+	}
+
+	err = f.Close()
+	if err != nil {
+		return fmt.Errorf("close ndjson file: %w", err)
+	}
+
+	return nil
+}
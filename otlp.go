@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	prombridge "go.opentelemetry.io/contrib/bridges/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// Protocols supported for OTLP export.
+const (
+	OTLPProtocolHTTP = "http"
+	OTLPProtocolGRPC = "grpc"
+)
+
+// ServiceName is the OpenTelemetry service name of the exporter.
+const ServiceName = "eci-prometheus-exporter"
+
+// ErrUnknownOTLPProtocol is returned when the OTLP protocol is neither http nor grpc.
+var ErrUnknownOTLPProtocol = errors.New("unknown OTLP protocol")
+
+// OTLPConfig configures the export of metrics to an OpenTelemetry collector.
+type OTLPConfig struct {
+	Endpoint string // e.g. "http://otel-collector:4318"
+	Protocol string
+	Interval time.Duration
+}
+
+// NewOTLPMeterProvider creates a meter provider that periodically pushes everything in the gatherer via OTLP.
+// The configured initiatives are attached to the resource.
+func NewOTLPMeterProvider(
+	ctx context.Context,
+	cfg OTLPConfig,
+	gatherer prometheus.Gatherer,
+	initiatives []RegistrationNumber,
+) (*sdkmetric.MeterProvider, error) {
+	exporter, err := newOTLPMetricExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := newResource(initiatives)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := sdkmetric.NewPeriodicReader(exporter,
+		sdkmetric.WithInterval(cfg.Interval),
+		sdkmetric.WithProducer(prombridge.NewMetricProducer(prombridge.WithGatherer(gatherer))),
+	)
+
+	return sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(reader),
+		sdkmetric.WithResource(res),
+	), nil
+}
+
+func newOTLPMetricExporter(ctx context.Context, cfg OTLPConfig) (sdkmetric.Exporter, error) {
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("parse OTLP endpoint: %w", err)
+	}
+
+	var exporter sdkmetric.Exporter
+
+	switch cfg.Protocol {
+	case OTLPProtocolHTTP:
+		opts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpointURL(endpoint.String())}
+		if endpoint.Path == "" || endpoint.Path == "/" {
+			opts = append(opts, otlpmetrichttp.WithURLPath("/v1/metrics"))
+		}
+
+		exporter, err = otlpmetrichttp.New(ctx, opts...)
+	case OTLPProtocolGRPC:
+		exporter, err = otlpmetricgrpc.New(ctx, otlpmetricgrpc.WithEndpointURL(endpoint.String()))
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownOTLPProtocol, cfg.Protocol)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("create OTLP exporter: %w", err)
+	}
+
+	return exporter, nil
+}
+
+func newResource(initiatives []RegistrationNumber) (*resource.Resource, error) {
+	ids := make([]string, 0, len(initiatives))
+	for _, i := range initiatives {
+		ids = append(ids, i.String())
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", ServiceName),
+		attribute.StringSlice("eci.initiative_ids", ids),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("create resource: %w", err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	eci "github.com/tvanriel/eci-prometheus-exporter"
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/protobuf/proto"
+)
+
+// collectorStandIn records the metric names and resource attributes it receives over OTLP/HTTP.
+type collectorStandIn struct {
+	mu         sync.Mutex
+	metrics    map[string]bool
+	attributes map[string]string
+}
+
+func (c *collectorStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/metrics" {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	req := &colmetricpb.ExportMetricsServiceRequest{}
+
+	if err := proto.Unmarshal(body, req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, rm := range req.GetResourceMetrics() {
+		for _, attr := range rm.GetResource().GetAttributes() {
+			c.attributes[attr.GetKey()] = attr.GetValue().String()
+		}
+
+		for _, sm := range rm.GetScopeMetrics() {
+			for _, m := range sm.GetMetrics() {
+				c.metrics[m.GetName()] = true
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestNewOTLPMeterProvider(t *testing.T) {
+	t.Parallel()
+
+	standIn := &collectorStandIn{metrics: map[string]bool{}, attributes: map[string]string{}}
+	collector := httptest.NewServer(standIn)
+	defer collector.Close()
+
+	server := ServerWantsCallForInitiativeID(MustParseRegistrationNumber("ECI(2024)000007"))(t)
+	defer server.Close()
+
+	initiatives := []eci.RegistrationNumber{*MustParseRegistrationNumber("ECI(2024)000007")}
+	app := eci.NewApplication(zaptest.NewLogger(t), server.URL, initiatives, "", http.DefaultClient)
+
+	registry := prometheus.NewRegistry()
+	app.MustRegisterWith(registry)
+
+	require.NoError(t, app.FetchAndUpdateMetrics(t.Context(), initiatives[0]))
+
+	provider, err := eci.NewOTLPMeterProvider(t.Context(), eci.OTLPConfig{
+		Endpoint: collector.URL,
+		Protocol: eci.OTLPProtocolHTTP,
+		Interval: time.Hour,
+	}, registry, initiatives)
+	require.NoError(t, err)
+
+	require.NoError(t, provider.ForceFlush(t.Context()))
+	require.NoError(t, provider.Shutdown(t.Context()))
+
+	standIn.mu.Lock()
+	defer standIn.mu.Unlock()
+
+	assert.True(t, standIn.metrics["eci_signatures"])
+	assert.True(t, standIn.metrics["eci_signature_threshold"])
+	assert.True(t, standIn.metrics["eci_api_duration_seconds"])
+	assert.Contains(t, standIn.attributes["service.name"], eci.ServiceName)
+	assert.Contains(t, standIn.attributes["eci.initiative_ids"], "ECI(2024)000007")
+}
+
+func TestNewOTLPMeterProvider_UnknownProtocol(t *testing.T) {
+	t.Parallel()
+
+	_, err := eci.NewOTLPMeterProvider(t.Context(), eci.OTLPConfig{
+		Endpoint: "http://localhost:4318",
+		Protocol: "carrier-pigeon",
+		Interval: time.Minute,
+	}, prometheus.NewRegistry(), nil)
+
+	require.ErrorContains(t, err, "unknown OTLP protocol")
+}
@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// PopulationSource is the source of the embedded population figures.
+const PopulationSource = "Eurostat, population on 1 January (tps00001)"
+
+// Population is the number of inhabitants of a member state.
+type Population struct {
+	Inhabitants int
+	Year        int // year of the source figure
+}
+
+// ErrInvalidPopulation is returned when the population dataset cannot be parsed.
+var ErrInvalidPopulation = errors.New("invalid population data")
+
+//go:embed data/population.csv
+var populationCSV []byte
+
+// Populations are the populations of the member states by country code.
+var Populations = mustParsePopulations(populationCSV)
+
+// ParsePopulations reads a CSV with a header and the columns country_code, population and year.
+func ParsePopulations(r io.Reader) (map[MemberCountryCode]Population, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPopulation, err)
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: missing header", ErrInvalidPopulation)
+	}
+
+	populations := make(map[MemberCountryCode]Population, len(records)-1)
+
+	for _, record := range records[1:] {
+		const columns = 3
+		if len(record) != columns {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPopulation, record)
+		}
+
+		inhabitants, err := strconv.Atoi(record[1])
+		if err != nil || inhabitants <= 0 {
+			return nil, fmt.Errorf("%w: population of %s: %q", ErrInvalidPopulation, record[0], record[1])
+		}
+
+		year, err := strconv.Atoi(record[2])
+		if err != nil {
+			return nil, fmt.Errorf("%w: year of %s: %q", ErrInvalidPopulation, record[0], record[2])
+		}
+
+		populations[Countries.MemberCountryCode(record[0])] = Population{Inhabitants: inhabitants, Year: year}
+	}
+
+	return populations, nil
+}
+
+func mustParsePopulations(data []byte) map[MemberCountryCode]Population {
+	populations, err := ParsePopulations(bytes.NewReader(data))
+	if err != nil {
+		panic(err)
+	}
+
+	return populations
+}
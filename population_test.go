@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	eci "github.com/tvanriel/eci-prometheus-exporter"
+)
+
+func TestPopulations(t *testing.T) {
+	t.Parallel()
+
+	th := eci.GetThresholds(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	assert.Len(t, eci.Populations, len(th))
+
+	for code := range th {
+		assert.Positive(t, eci.Populations[code].Inhabitants, code)
+		assert.Equal(t, 2024, eci.Populations[code].Year, code)
+	}
+}
+
+func TestParsePopulations(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		csv     string
+		want    map[eci.MemberCountryCode]eci.Population
+		wantErr assert.ErrorAssertionFunc
+	}{
+		"valid": {
+			csv:     "country_code,population,year\nNL,17942942,2024\n",
+			want:    map[eci.MemberCountryCode]eci.Population{"nl": {Inhabitants: 17942942, Year: 2024}},
+			wantErr: assert.NoError,
+		},
+		"empty": {
+			csv:     "",
+			want:    nil,
+			wantErr: errContains("missing header"),
+		},
+		"invalid population": {
+			csv:     "country_code,population,year\nnl,many,2024\n",
+			want:    nil,
+			wantErr: errContains("population of nl"),
+		},
+		"zero population": {
+			csv:     "country_code,population,year\nnl,0,2024\n",
+			want:    nil,
+			wantErr: errContains("population of nl"),
+		},
+		"invalid year": {
+			csv:     "country_code,population,year\nnl,17942942,recent\n",
+			want:    nil,
+			wantErr: errContains("year of nl"),
+		},
+		"missing column": {
+			csv:     "country_code,population,year\nnl,17942942\n",
+			want:    nil,
+			wantErr: errContains("invalid population data"),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := eci.ParsePopulations(strings.NewReader(tt.csv))
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// Names of the metrics exposed by the /probe endpoint.
+const (
+	MetricProbeSuccess  = "eci_probe_success"
+	MetricProbeDuration = "eci_probe_duration_seconds"
+)
+
+// maxProbeCacheEntries is the maximum number of reports the [Prober] caches.
+const maxProbeCacheEntries = 1000
+
+// Prober serves /probe?target=<initiative>, which fetches the initiative on request in the style of the
+// blackbox_exporter. Concurrent probes of the same initiative share one API call and results are cached for CacheTTL.
+// Uncached probes wait for the rate limiter of the scheduler, like the polls.
+type Prober struct {
+	App      *Application
+	CacheTTL time.Duration
+	Timeout  time.Duration
+
+	group singleflight.Group
+
+	mu    sync.Mutex
+	cache map[string]probeResult
+}
+
+type probeResult struct {
+	report  *ProgressResponse
+	fetched time.Time
+}
+
+// NewProber creates a [Prober] for the application.
+func NewProber(a *Application, cacheTTL, timeout time.Duration) *Prober {
+	return &Prober{
+		App:      a,
+		CacheTTL: cacheTTL,
+		Timeout:  timeout,
+		cache:    map[string]probeResult{},
+	}
+}
+
+// ServeHTTP implements [http.Handler].
+func (p *Prober) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	target := r.URL.Query().Get("target")
+
+	registrationNumber, err := ParseRegistrationNumber(target)
+	if err != nil {
+		http.Error(w, "invalid target "+target+": "+err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), p.Timeout)
+	defer cancel()
+
+	logger := p.App.Logger.With(zap.String("initiative_id", registrationNumber.String())).With(traceFields(ctx)...)
+
+	start := time.Now()
+
+	report, err := p.Probe(ctx, *registrationNumber)
+	if err != nil {
+		logger.Warn("Probe failed", zap.Error(err))
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(probeCollectors(*registrationNumber, report, time.Since(start))...)
+
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{EnableOpenMetrics: true}).ServeHTTP(w, r)
+}
+
+// Probe returns the report of the initiative, from the cache when it is younger than CacheTTL.
+func (p *Prober) Probe(ctx context.Context, registrationNumber RegistrationNumber) (*ProgressResponse, error) {
+	key := registrationNumber.String()
+
+	p.mu.Lock()
+	cached, ok := p.cache[key]
+	p.mu.Unlock()
+
+	if ok && time.Since(cached.fetched) < p.CacheTTL {
+		return cached.report, nil
+	}
+
+	v, err, _ := p.group.Do(key, func() (any, error) {
+		// The fetch is shared with concurrent probes, so it must not be cancelled when the first client goes away.
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), p.Timeout)
+		defer cancel()
+
+		// Probes share the request budget of the polls.
+		if p.App.Scheduler != nil && p.App.Scheduler.Limiter != nil {
+			err := p.App.Scheduler.Limiter.Wait(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("wait for rate limiter: %w", err)
+			}
+		}
+
+		report, err := p.App.Fetch(ctx, registrationNumber)
+		if err != nil {
+			return nil, err
+		}
+
+		p.store(key, report)
+
+		return report, nil
+	})
+	if err != nil {
+		return nil, err //nolint:wrapcheck // already wrapped by Fetch.
+	}
+
+	report, _ := v.(*ProgressResponse)
+
+	return report, nil
+}
+
+// store caches the report. Expired reports are dropped and at most maxProbeCacheEntries reports are kept, so probes
+// of arbitrary targets cannot grow the cache without bound.
+func (p *Prober) store(key string, report *ProgressResponse) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	maps.DeleteFunc(p.cache, func(_ string, cached probeResult) bool { return now.Sub(cached.fetched) >= p.CacheTTL })
+
+	if len(p.cache) < maxProbeCacheEntries {
+		p.cache[key] = probeResult{report: report, fetched: now}
+	}
+}
+
+// probeCollectors creates the metrics of a single probe. A nil report results in a failed probe.
+func probeCollectors(registrationNumber RegistrationNumber, report *ProgressResponse, duration time.Duration) []prometheus.Collector {
+	initiativeID := registrationNumber.String()
+
+	success := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:        MetricProbeSuccess,
+		Help:        "Whether the ECI API returned a report for the initiative",
+		ConstLabels: prometheus.Labels{"initiative_id": initiativeID},
+	})
+
+	probeDuration := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:        MetricProbeDuration,
+		Help:        "Duration of the probe in seconds",
+		ConstLabels: prometheus.Labels{"initiative_id": initiativeID},
+	})
+	probeDuration.Set(duration.Seconds())
+
+	reports := NewReportCollector()
+	collectors := []prometheus.Collector{success, probeDuration, reports}
+
+	if report == nil {
+		return collectors
+	}
+
+	registrationDate, err := time.Parse("02/01/2006", report.RegistrationDate)
+	if err != nil {
+		return collectors
+	}
+
+	reports.Set(registrationNumber, report, GetThresholds(registrationDate))
+	success.Set(1)
+
+	return collectors
+}
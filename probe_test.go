@@ -0,0 +1,182 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	eci "github.com/tvanriel/eci-prometheus-exporter"
+	"go.uber.org/zap/zaptest"
+	"golang.org/x/time/rate"
+)
+
+func TestProber_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		target     string
+		status     int
+		wantStatus int
+		want       []string
+	}{
+		"valid target": {
+			target:     "ECI(2024)000007",
+			status:     http.StatusOK,
+			wantStatus: http.StatusOK,
+			want: []string{
+				`eci_probe_success{initiative_id="ECI(2024)000007"} 1`,
+				`eci_signatures{country_code="NL",initiative_id="ECI(2024)000007"} 75811`,
+				`eci_signature_threshold{country_code="NL",initiative_id="ECI(2024)000007"} 20445`,
+			},
+		},
+		"failing API": {
+			target:     "ECI(2024)000007",
+			status:     http.StatusInternalServerError,
+			wantStatus: http.StatusOK,
+			want:       []string{`eci_probe_success{initiative_id="ECI(2024)000007"} 0`},
+		},
+		"invalid target": {
+			target:     "045",
+			status:     http.StatusOK,
+			wantStatus: http.StatusBadRequest,
+			want:       []string{"invalid target 045"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(defaultResponse))
+			}))
+			defer server.Close()
+
+			app := eci.NewApplication(zaptest.NewLogger(t), server.URL, nil, "", http.DefaultClient)
+
+			rec := httptest.NewRecorder()
+			app.HTTPServer.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/probe?target="+tt.target, nil))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+
+			body, err := io.ReadAll(rec.Body)
+			require.NoError(t, err)
+
+			for _, want := range tt.want {
+				assert.Contains(t, string(body), want)
+			}
+		})
+	}
+}
+
+func TestProber_ProbeCaches(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		_, _ = w.Write([]byte(defaultResponse))
+	}))
+	defer server.Close()
+
+	rn := *MustParseRegistrationNumber("ECI(2024)000007")
+	app := eci.NewApplication(zaptest.NewLogger(t), server.URL, nil, "", http.DefaultClient)
+	prober := eci.NewProber(app, time.Hour, time.Second)
+
+	for range 3 {
+		report, err := prober.Probe(t.Context(), rn)
+		require.NoError(t, err)
+		assert.Equal(t, 1_149_248, report.SOSReport.TotalSignatures)
+	}
+
+	assert.Equal(t, int32(1), calls.Load())
+
+	prober.CacheTTL = 0
+
+	_, err := prober.Probe(t.Context(), rn)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestProber_ProbeDoesNotHidePollChanges(t *testing.T) {
+	t.Parallel()
+
+	var version atomic.Int32
+
+	version.Store(1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := fmt.Sprintf(`"v%d"`, version.Load())
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte(strings.Replace(defaultResponse, `"NL","total":75811`,
+			fmt.Sprintf(`"NL","total":%d`, 75810+version.Load()), 1)))
+	}))
+	t.Cleanup(server.Close)
+
+	rn := *MustParseRegistrationNumber("ECI(2024)000007")
+	app := eci.NewApplication(zaptest.NewLogger(t), server.URL, []eci.RegistrationNumber{rn}, "", http.DefaultClient)
+
+	nl := func() int {
+		for _, country := range app.Reports.Snapshot()[rn.String()] {
+			if country.CountryCode == "NL" {
+				return country.Signatures
+			}
+		}
+
+		return 0
+	}
+
+	require.NoError(t, app.FetchAndUpdateMetrics(t.Context(), rn))
+	require.Equal(t, 75811, nl())
+
+	version.Store(2)
+
+	report, err := app.Prober.Probe(t.Context(), rn)
+	require.NoError(t, err)
+	require.Equal(t, 1_149_248, report.SOSReport.TotalSignatures)
+
+	require.NoError(t, app.FetchAndUpdateMetrics(t.Context(), rn))
+	assert.Equal(t, 75812, nl(), "the poll after a probe still updates eci_signatures")
+}
+
+func TestProber_ProbeRateLimit(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		_, _ = w.Write([]byte(defaultResponse))
+	}))
+	defer server.Close()
+
+	rn := *MustParseRegistrationNumber("ECI(2024)000007")
+	app := eci.NewApplication(zaptest.NewLogger(t), server.URL, []eci.RegistrationNumber{rn}, "", http.DefaultClient)
+	app.Scheduler = eci.NewScheduler(app.TrackedInitiatives(), time.Hour, 1, rate.Every(time.Hour), app.FetchAndUpdateMetrics)
+
+	// Nothing is cached, so both probes need a token of the limiter.
+	prober := eci.NewProber(app, 0, 100*time.Millisecond)
+
+	_, err := prober.Probe(t.Context(), rn)
+	require.NoError(t, err)
+
+	_, err = prober.Probe(t.Context(), rn)
+	require.ErrorContains(t, err, "wait for rate limiter", "the limiter has no token left")
+	assert.Equal(t, int32(1), calls.Load())
+}
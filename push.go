@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/push"
+	"go.uber.org/zap"
+)
+
+// Pushgateway is the destination for metrics pushed in one-shot mode.
+type Pushgateway struct {
+	URL      string
+	Job      string
+	Grouping map[string]string
+}
+
+// ErrInvalidGrouping is returned when a grouping key is not formatted as key=value.
+var ErrInvalidGrouping = errors.New("invalid grouping key")
+
+// ParseGrouping reads a comma-separated list of key=value pairs into a grouping key.
+func ParseGrouping(grouping string) (map[string]string, error) {
+	labels := map[string]string{}
+
+	if grouping == "" {
+		return labels, nil
+	}
+
+	for _, pair := range strings.Split(grouping, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" || value == "" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidGrouping, pair)
+		}
+
+		labels[key] = value
+	}
+
+	return labels, nil
+}
+
+// RunOnce fetches every configured initiative once and returns the joined errors of the failed fetches.
+func (a *Application) RunOnce(ctx context.Context, timeout time.Duration) error {
+	var errs []error
+
+	for _, registrationNumber := range a.Initiatives {
+		fetchCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := a.FetchAndUpdateMetrics(fetchCtx, registrationNumber)
+
+		cancel()
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", registrationNumber.String(), err))
+		}
+	}
+
+	if a.Textfile != nil {
+		err := a.Textfile.Write()
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Push pushes the signature, threshold and API duration metrics to the Pushgateway.
+func (a *Application) Push(ctx context.Context, gateway Pushgateway) error {
+	pusher := push.New(gateway.URL, gateway.Job).
+		Collector(a.Reports).
+		Collector(a.APIDurationVec).
+		Client(a.HTTPClient)
+
+	keys := make([]string, 0, len(gateway.Grouping))
+	for k := range gateway.Grouping {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		pusher = pusher.Grouping(k, gateway.Grouping[k])
+	}
+
+	err := pusher.PushContext(ctx)
+	if err != nil {
+		a.Logger.Error("Cannot push to Pushgateway", zap.String("url", gateway.URL), zap.Error(err))
+
+		return fmt.Errorf("push metrics: %w", err)
+	}
+
+	a.Logger.Info("Pushed metrics to Pushgateway", zap.String("url", gateway.URL), zap.String("job", gateway.Job))
+
+	return nil
+}
@@ -0,0 +1,140 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	eci "github.com/tvanriel/eci-prometheus-exporter"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestParseGrouping(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		grouping string
+		want     map[string]string
+		wantErr  assert.ErrorAssertionFunc
+	}{
+		"empty": {
+			grouping: "",
+			want:     map[string]string{},
+			wantErr:  assert.NoError,
+		},
+		"multiple pairs": {
+			grouping: "instance=cron,env=prod",
+			want:     map[string]string{"instance": "cron", "env": "prod"},
+			wantErr:  assert.NoError,
+		},
+		"missing value": {
+			grouping: "instance=",
+			want:     nil,
+			wantErr:  errContains("invalid grouping key"),
+		},
+		"no separator": {
+			grouping: "instance",
+			want:     nil,
+			wantErr:  errContains("invalid grouping key"),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, gotErr := eci.ParseGrouping(tt.grouping)
+
+			tt.wantErr(t, gotErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestApplication_RunOnce(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		server  Testserver
+		wantErr assert.ErrorAssertionFunc
+	}{
+		"all initiatives succeed": {
+			server:  ServerWantsCallForInitiativeID(MustParseRegistrationNumber("ECI(2024)000007")),
+			wantErr: assert.NoError,
+		},
+		"failing initiative is reported": {
+			server:  BrokenAF,
+			wantErr: errContains("ECI(2024)000007"),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server := tt.server(t)
+			defer server.Close()
+
+			app := eci.NewApplication(
+				zaptest.NewLogger(t),
+				server.URL,
+				[]eci.RegistrationNumber{*MustParseRegistrationNumber("ECI(2024)000007")},
+				"",
+				http.DefaultClient,
+			)
+
+			tt.wantErr(t, app.RunOnce(t.Context(), time.Second))
+		})
+	}
+}
+
+func TestApplication_Push(t *testing.T) {
+	t.Parallel()
+
+	var (
+		method, path string
+		body         []byte
+	)
+
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		body, _ = io.ReadAll(r.Body)
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer gateway.Close()
+
+	server := ServerWantsCallForInitiativeID(MustParseRegistrationNumber("ECI(2024)000007"))(t)
+	defer server.Close()
+
+	app := eci.NewApplication(
+		zaptest.NewLogger(t),
+		server.URL,
+		[]eci.RegistrationNumber{*MustParseRegistrationNumber("ECI(2024)000007")},
+		"",
+		http.DefaultClient,
+	)
+
+	require.NoError(t, app.RunOnce(t.Context(), time.Second))
+	require.NoError(t, app.Push(t.Context(), eci.Pushgateway{
+		URL:      gateway.URL,
+		Job:      "eci_exporter",
+		Grouping: map[string]string{"instance": "cron"},
+	}))
+
+	assert.Equal(t, http.MethodPut, method)
+	assert.Equal(t, "/metrics/job/eci_exporter/instance/cron", path)
+	assert.NotEmpty(t, body)
+}
+
+func TestApplication_PushUnreachable(t *testing.T) {
+	t.Parallel()
+
+	app := eci.NewApplication(zaptest.NewLogger(t), "", []eci.RegistrationNumber{}, "", http.DefaultClient)
+
+	require.ErrorContains(t, app.Push(t.Context(), eci.Pushgateway{URL: "http://127.0.0.1:1", Job: "eci"}), "push metrics")
+}
@@ -0,0 +1,159 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Outcomes of a refresh.
+const (
+	RefreshOK     = "ok"
+	RefreshFailed = "failed"
+)
+
+// RefreshResult is the outcome of refreshing one initiative.
+type RefreshResult struct {
+	InitiativeID string  `json:"initiative_id"`
+	Status       string  `json:"status"`
+	Error        string  `json:"error,omitempty"`
+	Signatures   int     `json:"signatures,omitempty"`
+	Duration     float64 `json:"duration_seconds"`
+}
+
+// RefreshResponse is the response of the refresh endpoint.
+type RefreshResponse struct {
+	Results []RefreshResult `json:"results"`
+}
+
+// Refresher serves POST /api/v1/refresh and /api/v1/refresh/{id}, which poll all or one initiative right away instead
+// of waiting for the next scheduled poll. The polls run in the [Scheduler], so concurrent refreshes and scheduled
+// polls of the same initiative share one poll and respect its rate limit.
+type Refresher struct {
+	App     *Application
+	Timeout time.Duration
+
+	mux *http.ServeMux
+}
+
+// NewRefresher creates a [Refresher] for the application.
+func NewRefresher(a *Application, timeout time.Duration) *Refresher {
+	r := &Refresher{App: a, Timeout: timeout, mux: http.NewServeMux()}
+
+	r.mux.HandleFunc("POST /api/v1/refresh", r.refreshAll)
+	r.mux.HandleFunc("POST /api/v1/refresh/{id}", r.refreshOne)
+
+	return r
+}
+
+// ServeHTTP implements [http.Handler].
+func (r *Refresher) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mux.ServeHTTP(w, req)
+}
+
+func (r *Refresher) refreshAll(w http.ResponseWriter, req *http.Request) {
+	r.respond(w, r.RefreshAll(req.Context()))
+}
+
+func (r *Refresher) refreshOne(w http.ResponseWriter, req *http.Request) {
+	id := req.PathValue("id")
+
+	registrationNumber, err := ParseRegistrationNumber(id)
+	if err != nil {
+		http.Error(w, "invalid initiative "+id+": "+err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	if !r.App.Tracked(*registrationNumber) {
+		http.Error(w, "initiative "+registrationNumber.String()+" is not tracked", http.StatusNotFound)
+
+		return
+	}
+
+	r.respond(w, []RefreshResult{r.Refresh(req.Context(), *registrationNumber)})
+}
+
+// RefreshAll refreshes every tracked initiative concurrently.
+func (r *Refresher) RefreshAll(ctx context.Context) []RefreshResult {
+	initiatives := r.App.TrackedInitiatives()
+	results := make([]RefreshResult, len(initiatives))
+
+	var wg sync.WaitGroup
+
+	for i, registrationNumber := range initiatives {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			results[i] = r.Refresh(ctx, registrationNumber)
+		}()
+	}
+
+	wg.Wait()
+
+	slices.SortFunc(results, func(a, b RefreshResult) int { return strings.Compare(a.InitiativeID, b.InitiativeID) })
+
+	return results
+}
+
+// Refresh polls the initiative through the scheduler and waits for the result, at most Timeout.
+func (r *Refresher) Refresh(ctx context.Context, registrationNumber RegistrationNumber) RefreshResult {
+	key := registrationNumber.String()
+	result := RefreshResult{InitiativeID: key, Status: RefreshOK}
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+
+	err := ErrNotScheduled
+	if r.App.Scheduler != nil {
+		err = r.App.Scheduler.PollNow(ctx, registrationNumber)
+	}
+
+	result.Duration = time.Since(start).Seconds()
+
+	if err != nil {
+		result.Status = RefreshFailed
+		result.Error = err.Error()
+
+		return result
+	}
+
+	// The total of the report, which eci_report_inconsistency compares the member states against.
+	if countries := r.App.Reports.Snapshot()[key]; len(countries) > 0 {
+		result.Signatures = countries[0].Total
+	}
+
+	return result
+}
+
+// respond writes the results, with 502 Bad Gateway when a refresh failed.
+func (r *Refresher) respond(w http.ResponseWriter, results []RefreshResult) {
+	status := http.StatusOK
+
+	for _, result := range results {
+		if result.Status != RefreshOK {
+			status = http.StatusBadGateway
+
+			r.App.Logger.Warn("Refresh failed", zap.String("initiative_id", result.InitiativeID), zap.String("error", result.Error))
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	err := json.NewEncoder(w).Encode(RefreshResponse{Results: results})
+	if err != nil {
+		r.App.Logger.Debug("Cannot write refresh response")
+	}
+}
@@ -0,0 +1,232 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	eci "github.com/tvanriel/eci-prometheus-exporter"
+	"go.uber.org/zap/zaptest"
+	"golang.org/x/time/rate"
+)
+
+// countingServer answers every initiative with the default response after release is closed.
+func countingServer(t *testing.T, calls *atomic.Int32, release <-chan struct{}) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		<-release
+
+		w.Header().Add("Content-Type", "application/json")
+		_, _ = w.Write([]byte(defaultResponse))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// startScheduler polls the initiatives of the application with a running scheduler, like the exporter does.
+func startScheduler(t *testing.T, app *eci.Application, limit rate.Limit) {
+	t.Helper()
+
+	app.Scheduler = eci.NewScheduler(app.TrackedInitiatives(), time.Hour, 2, limit, app.FetchAndUpdateMetrics)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go app.Scheduler.Run(ctx)
+}
+
+func TestRefresher_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	released := make(chan struct{})
+	close(released)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(failing.Close)
+
+	initiatives := []eci.RegistrationNumber{
+		*MustParseRegistrationNumber("ECI(2024)000008"),
+		*MustParseRegistrationNumber("ECI(2024)000007"),
+	}
+
+	tests := map[string]struct {
+		apiURL string
+		target string
+		want   int
+		result []eci.RefreshResult
+	}{
+		"all": {
+			target: "/api/v1/refresh",
+			want:   http.StatusOK,
+			result: []eci.RefreshResult{
+				{InitiativeID: "ECI(2024)000007", Status: eci.RefreshOK, Signatures: 1_149_248},
+				{InitiativeID: "ECI(2024)000008", Status: eci.RefreshOK, Signatures: 1_149_248},
+			},
+		},
+		"one": {
+			target: "/api/v1/refresh/ECI(2024)000007",
+			want:   http.StatusOK,
+			result: []eci.RefreshResult{{InitiativeID: "ECI(2024)000007", Status: eci.RefreshOK, Signatures: 1_149_248}},
+		},
+		"failing API": {
+			apiURL: failing.URL,
+			target: "/api/v1/refresh/ECI(2024)000007",
+			want:   http.StatusBadGateway,
+			result: []eci.RefreshResult{{InitiativeID: "ECI(2024)000007", Status: eci.RefreshFailed}},
+		},
+		"not tracked": {
+			target: "/api/v1/refresh/ECI(2025)000001",
+			want:   http.StatusNotFound,
+		},
+		"invalid initiative": {
+			target: "/api/v1/refresh/nope",
+			want:   http.StatusBadRequest,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls atomic.Int32
+
+			apiURL := tt.apiURL
+			if apiURL == "" {
+				apiURL = countingServer(t, &calls, released).URL
+			}
+
+			app := eci.NewApplication(zaptest.NewLogger(t), apiURL, initiatives, "", http.DefaultClient)
+			startScheduler(t, app, rate.Inf)
+
+			rec := httptest.NewRecorder()
+			app.HTTPServer.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, tt.target, nil))
+
+			require.Equal(t, tt.want, rec.Code, rec.Body.String())
+
+			if tt.result == nil {
+				return
+			}
+
+			var got eci.RefreshResponse
+
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+
+			for i := range got.Results {
+				assert.Positive(t, got.Results[i].Duration)
+				got.Results[i].Duration = 0
+
+				if got.Results[i].Status == eci.RefreshFailed {
+					assert.Contains(t, got.Results[i].Error, eci.ErrNon200.Error())
+					got.Results[i].Error = ""
+				}
+			}
+
+			assert.Equal(t, tt.result, got.Results)
+		})
+	}
+}
+
+func TestRefresher_RefreshSharesScheduledPoll(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	release := make(chan struct{})
+	server := countingServer(t, &calls, release)
+	rn := *MustParseRegistrationNumber("ECI(2024)000007")
+	app := eci.NewApplication(zaptest.NewLogger(t), server.URL, []eci.RegistrationNumber{rn}, "", http.DefaultClient)
+	startScheduler(t, app, rate.Inf)
+
+	// The first scheduled poll is running.
+	assert.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, 10*time.Millisecond)
+
+	var wg sync.WaitGroup
+
+	results := make([]eci.RefreshResult, 5)
+
+	for i := range results {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			results[i] = app.Refresher.Refresh(t.Context(), rn)
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond) // let the refreshes wait for the running poll.
+	assert.Equal(t, int32(1), calls.Load(), "a refresh never runs next to a scheduled poll")
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(2), calls.Load(), "the refreshes share one poll after the scheduled poll")
+
+	for _, result := range results {
+		assert.Equal(t, eci.RefreshOK, result.Status)
+	}
+
+	next := testutil.ToFloat64(app.Scheduler.NextPoll.WithLabelValues(rn.String()))
+	assert.InDelta(t, float64(time.Now().Add(time.Hour).Unix()), next, 5, "the next poll is an interval after the refresh")
+}
+
+func TestRefresher_RefreshRateLimit(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	released := make(chan struct{})
+	close(released)
+
+	server := countingServer(t, &calls, released)
+	rn := *MustParseRegistrationNumber("ECI(2024)000007")
+	app := eci.NewApplication(zaptest.NewLogger(t), server.URL, []eci.RegistrationNumber{rn}, "", http.DefaultClient)
+	app.Refresher.Timeout = 100 * time.Millisecond
+	startScheduler(t, app, rate.Every(time.Hour))
+
+	// The scheduled poll takes the only token of the limiter.
+	assert.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, eci.RefreshFailed, app.Refresher.Refresh(t.Context(), rn).Status, "the limiter has no token left")
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestRefresher_RefreshWithoutScheduler(t *testing.T) {
+	t.Parallel()
+
+	rn := *MustParseRegistrationNumber("ECI(2024)000007")
+	app := eci.NewApplication(zaptest.NewLogger(t), "http://localhost", []eci.RegistrationNumber{rn}, "", http.DefaultClient)
+
+	result := app.Refresher.Refresh(t.Context(), rn)
+	assert.Equal(t, eci.RefreshFailed, result.Status)
+	assert.Equal(t, eci.ErrNotScheduled.Error(), result.Error)
+}
+
+func TestRefresher_RefreshReturnsReportTotal(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(inconsistentResponse))
+	}))
+	t.Cleanup(server.Close)
+
+	rn := *MustParseRegistrationNumber("ECI(2024)000007")
+	app := eci.NewApplication(zaptest.NewLogger(t), server.URL, []eci.RegistrationNumber{rn}, "", http.DefaultClient)
+	startScheduler(t, app, rate.Inf)
+
+	result := app.Refresher.Refresh(t.Context(), rn)
+	require.Equal(t, eci.RefreshOK, result.Status, result.Error)
+	assert.Equal(t, 100, result.Signatures, "the total of the report, not the sum of the member states")
+}
@@ -3,7 +3,10 @@ package main
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"regexp"
+	"strings"
+	"unicode"
 )
 
 // RegistrationNumber is a parsed registration number.
@@ -12,27 +15,119 @@ type RegistrationNumber struct {
 }
 
 // String implements [fmt.Stringer].
-func (r *RegistrationNumber) String() string {
+func (r RegistrationNumber) String() string {
 	return fmt.Sprintf("%s(%s)%s", r.Auth, r.Year, r.Number)
 }
 
+// Set implements [flag.Value].
+func (r *RegistrationNumber) Set(s string) error {
+	rn, err := ParseRegistrationNumber(s)
+	if err != nil {
+		return err
+	}
+
+	*r = *rn
+
+	return nil
+}
+
+// MarshalText implements [encoding.TextMarshaler], which also encodes registration numbers as JSON strings.
+func (r RegistrationNumber) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (r *RegistrationNumber) UnmarshalText(text []byte) error {
+	return r.Set(string(text))
+}
+
 // ErrInvalidRegistrationNumber is returned when the registration number layout is invalid.
 var ErrInvalidRegistrationNumber = errors.New("invalid format")
 
-// ParseRegistrationNumber reads the Registration number from a string.
+// defaultAuth is the authority of registration numbers that are given without one.
+const defaultAuth = "ECI"
+
+// numberDigits is the length of the zero-padded number of a registration number.
+const numberDigits = 6
+
+var (
+	canonicalRegistrationNumber = regexp.MustCompile(`^([A-Z]+)\((\d{4})\)(\d{1,6})$`)
+	shortRegistrationNumber     = regexp.MustCompile(`^(\d{4})/(\d{1,6})$`)
+	urlRegistrationNumber       = regexp.MustCompile(`/(\d{4})/(\d{1,6})(?:[_/]|$)`)
+)
+
+// ParseRegistrationNumber reads the Registration number from a string. Besides the canonical ECI(2024)000007 it
+// accepts 2024/000007, lowercase and whitespace variants and register URLs such as
+// https://citizens-initiative.europa.eu/initiatives/details/2024/000007_en.
 func ParseRegistrationNumber(rn string) (*RegistrationNumber, error) {
-	re := regexp.MustCompile(`^([A-Z]+)\((\d{4})\)(\d{6})$`)
+	if u, err := url.Parse(strings.TrimSpace(rn)); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		matches := urlRegistrationNumber.FindStringSubmatch(u.Path)
+		if matches == nil {
+			return nil, fmt.Errorf("%w: no registration number in URL %s", ErrInvalidRegistrationNumber, rn)
+		}
+
+		return newRegistrationNumber(defaultAuth, matches[1], matches[2]), nil
+	}
+
+	compact := strings.ToUpper(strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+
+		return r
+	}, rn))
 
-	matches := re.FindStringSubmatch(rn)
+	if matches := canonicalRegistrationNumber.FindStringSubmatch(compact); matches != nil {
+		return newRegistrationNumber(matches[1], matches[2], matches[3]), nil
+	}
 
-	//nolint:mnd // regex is just above.
-	if len(matches) != 4 {
-		return nil, ErrInvalidRegistrationNumber
+	if matches := shortRegistrationNumber.FindStringSubmatch(compact); matches != nil {
+		return newRegistrationNumber(defaultAuth, matches[1], matches[2]), nil
 	}
 
+	return nil, ErrInvalidRegistrationNumber
+}
+
+func newRegistrationNumber(auth, year, number string) *RegistrationNumber {
 	return &RegistrationNumber{
-		Auth:   matches[1],
-		Year:   matches[2],
-		Number: matches[3],
-	}, nil
+		Auth:   auth,
+		Year:   year,
+		Number: strings.Repeat("0", numberDigits-len(number)) + number,
+	}
+}
+
+// RegistrationNumbers is a list of registration numbers that implements [flag.Value]. The flag accepts
+// comma-separated registration numbers and can be repeated.
+type RegistrationNumbers []RegistrationNumber
+
+// String implements [flag.Value].
+func (r *RegistrationNumbers) String() string {
+	if r == nil {
+		return ""
+	}
+
+	ids := make([]string, 0, len(*r))
+	for _, rn := range *r {
+		ids = append(ids, rn.String())
+	}
+
+	return strings.Join(ids, ",")
+}
+
+// Set implements [flag.Value].
+func (r *RegistrationNumbers) Set(s string) error {
+	for _, id := range strings.Split(s, ",") {
+		if strings.TrimSpace(id) == "" {
+			continue
+		}
+
+		rn, err := ParseRegistrationNumber(id)
+		if err != nil {
+			return fmt.Errorf("parse registration number %q: %w", id, err)
+		}
+
+		*r = append(*r, *rn)
+	}
+
+	return nil
 }
@@ -1,10 +1,14 @@
 package main_test
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	eci "github.com/tvanriel/eci-prometheus-exporter"
 )
 
@@ -42,6 +46,46 @@ func TestParseRegistrationNumber(t *testing.T) {
 			want:    nil,
 			wantErr: errContains("invalid format"),
 		},
+		"lowercase with whitespace": {
+			rn:      " eci (2024) 000007\n",
+			want:    &eci.RegistrationNumber{Auth: "ECI", Year: "2024", Number: "000007"},
+			wantErr: assert.NoError,
+		},
+		"unpadded number": {
+			rn:      "ECI(2024)7",
+			want:    &eci.RegistrationNumber{Auth: "ECI", Year: "2024", Number: "000007"},
+			wantErr: assert.NoError,
+		},
+		"year and number": {
+			rn:      "2024/000007",
+			want:    &eci.RegistrationNumber{Auth: "ECI", Year: "2024", Number: "000007"},
+			wantErr: assert.NoError,
+		},
+		"register URL": {
+			rn:      "https://citizens-initiative.europa.eu/initiatives/details/2024/000007_en",
+			want:    &eci.RegistrationNumber{Auth: "ECI", Year: "2024", Number: "000007"},
+			wantErr: assert.NoError,
+		},
+		"API URL": {
+			rn:      "https://register.eci.ec.europa.eu/core/api/register/details/2024/000007",
+			want:    &eci.RegistrationNumber{Auth: "ECI", Year: "2024", Number: "000007"},
+			wantErr: assert.NoError,
+		},
+		"URL without registration number": {
+			rn:      "https://citizens-initiative.europa.eu/initiatives",
+			want:    nil,
+			wantErr: errContains("invalid format"),
+		},
+		"signature collection number": {
+			rn:      "043",
+			want:    nil,
+			wantErr: errContains("invalid format"),
+		},
+		"number too long": {
+			rn:      "ECI(2024)0000007",
+			want:    nil,
+			wantErr: errContains("invalid format"),
+		},
 	}
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -55,6 +99,59 @@ func TestParseRegistrationNumber(t *testing.T) {
 	}
 }
 
+func TestRegistrationNumber_Encoding(t *testing.T) {
+	t.Parallel()
+
+	type config struct {
+		Initiative  eci.RegistrationNumber            `json:"initiative"`
+		Initiatives []eci.RegistrationNumber          `json:"initiatives"`
+		Labels      map[eci.RegistrationNumber]string `json:"labels"`
+	}
+
+	var got config
+
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"initiative": "eci(2024)000007",
+		"initiatives": ["2024/000008"],
+		"labels": {"ECI(2025)000001": "new"}
+	}`), &got))
+
+	want := config{
+		Initiative:  *MustParseRegistrationNumber("ECI(2024)000007"),
+		Initiatives: []eci.RegistrationNumber{*MustParseRegistrationNumber("ECI(2024)000008")},
+		Labels:      map[eci.RegistrationNumber]string{*MustParseRegistrationNumber("ECI(2025)000001"): "new"},
+	}
+	assert.Equal(t, want, got)
+
+	encoded, err := json.Marshal(got)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"initiative": "ECI(2024)000007",
+		"initiatives": ["ECI(2024)000008"],
+		"labels": {"ECI(2025)000001": "new"}
+	}`, string(encoded))
+
+	require.ErrorIs(t, json.Unmarshal([]byte(`{"initiative": "043"}`), &got), eci.ErrInvalidRegistrationNumber)
+}
+
+func TestRegistrationNumbers_Set(t *testing.T) {
+	t.Parallel()
+
+	var initiatives eci.RegistrationNumbers
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Var(&initiatives, "initiatives", "")
+
+	require.NoError(t, fs.Parse([]string{"-initiatives=ECI(2024)000007, 2024/8,", "-initiatives", "ECI(2025)000001"}))
+	assert.Equal(t, "ECI(2024)000007,ECI(2024)000008,ECI(2025)000001", initiatives.String())
+
+	err := initiatives.Set("043,045,098")
+	require.ErrorIs(t, err, eci.ErrInvalidRegistrationNumber)
+	assert.Contains(t, err.Error(), `"043"`)
+	require.Error(t, fs.Parse([]string{"-initiatives=043"}))
+}
+
 func MustParseRegistrationNumber(str string) *eci.RegistrationNumber {
 	rn, err := eci.ParseRegistrationNumber(str)
 	if err != nil {
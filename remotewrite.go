@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// RemoteWriteWriter writes samples to a Prometheus remote-write endpoint.
+type RemoteWriteWriter struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+// EncodeWriteRequest encodes the samples as a remote-write prometheus.WriteRequest protobuf message.
+//
+//nolint:mnd // protobuf field numbers.
+func EncodeWriteRequest(samples []Sample) []byte {
+	var req []byte
+
+	for _, s := range samples {
+		labels := make(map[string]string, len(s.Labels)+1)
+		for k, v := range s.Labels {
+			labels[k] = v
+		}
+
+		labels["__name__"] = s.Name
+
+		names := make([]string, 0, len(labels))
+		for k := range labels {
+			names = append(names, k)
+		}
+
+		sort.Strings(names)
+
+		var ts []byte
+
+		for _, name := range names {
+			var label []byte
+			label = protowire.AppendTag(label, 1, protowire.BytesType)
+			label = protowire.AppendString(label, name)
+			label = protowire.AppendTag(label, 2, protowire.BytesType)
+			label = protowire.AppendString(label, labels[name])
+
+			ts = protowire.AppendTag(ts, 1, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, label)
+		}
+
+		var sample []byte
+		sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+		sample = protowire.AppendFixed64(sample, math.Float64bits(s.Value))
+		sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+		sample = protowire.AppendVarint(sample, uint64(s.Time.UnixMilli())) //nolint:gosec // protobuf int64 encoding.
+
+		ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, sample)
+
+		req = protowire.AppendTag(req, 1, protowire.BytesType)
+		req = protowire.AppendBytes(req, ts)
+	}
+
+	return req
+}
+
+// WriteSamples implements [SampleWriter].
+func (w *RemoteWriteWriter) WriteSamples(ctx context.Context, samples []Sample) error {
+	body := snappy.Encode(nil, EncodeWriteRequest(samples))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("make request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	return doSinkRequest(w.HTTPClient, req)
+}
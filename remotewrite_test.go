@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main_test
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	eci "github.com/tvanriel/eci-prometheus-exporter"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// decodeFields splits a protobuf message into its length-delimited and fixed/varint fields.
+func decodeFields(t *testing.T, b []byte) map[protowire.Number][][]byte {
+	t.Helper()
+
+	fields := map[protowire.Number][][]byte{}
+
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+
+		n = protowire.ConsumeFieldValue(num, typ, b)
+		require.GreaterOrEqual(t, n, 0)
+
+		value := b[:n]
+		if typ == protowire.BytesType {
+			value, _ = protowire.ConsumeBytes(b)
+		}
+
+		fields[num] = append(fields[num], value)
+		b = b[n:]
+	}
+
+	return fields
+}
+
+func TestEncodeWriteRequest(t *testing.T) {
+	t.Parallel()
+
+	at := time.UnixMilli(1717459200000)
+	req := eci.EncodeWriteRequest([]eci.Sample{{
+		Name:   "eci_signatures",
+		Labels: map[string]string{"initiative_id": "ECI(2024)000007", "country_code": "NL"},
+		Value:  75811,
+		Time:   at,
+	}})
+
+	series := decodeFields(t, req)[1]
+	require.Len(t, series, 1)
+
+	ts := decodeFields(t, series[0])
+
+	labels := make([][2]string, 0, len(ts[1]))
+	for _, l := range ts[1] {
+		fields := decodeFields(t, l)
+		labels = append(labels, [2]string{string(fields[1][0]), string(fields[2][0])})
+	}
+
+	assert.Equal(t, [][2]string{
+		{"__name__", "eci_signatures"},
+		{"country_code", "NL"},
+		{"initiative_id", "ECI(2024)000007"},
+	}, labels)
+
+	sample := decodeFields(t, ts[2][0])
+	value, _ := protowire.ConsumeFixed64(sample[1][0])
+	timestamp, _ := protowire.ConsumeVarint(sample[2][0])
+
+	assert.InDelta(t, 75811.0, math.Float64frombits(value), 0)
+	assert.Equal(t, uint64(at.UnixMilli()), timestamp)
+}
+
+func TestRemoteWriteWriter_WriteSamples(t *testing.T) {
+	t.Parallel()
+
+	var (
+		headers http.Header
+		body    []byte
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		body, _ = io.ReadAll(r.Body)
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	writer := &eci.RemoteWriteWriter{URL: server.URL, HTTPClient: http.DefaultClient}
+	samples := eci.ReportSamples(*MustParseRegistrationNumber("ECI(2024)000007"), testReport(t), time.Now())
+
+	require.NoError(t, writer.WriteSamples(t.Context(), samples))
+
+	assert.Equal(t, "snappy", headers.Get("Content-Encoding"))
+	assert.Equal(t, "0.1.0", headers.Get("X-Prometheus-Remote-Write-Version"))
+
+	decoded, err := snappy.Decode(nil, body)
+	require.NoError(t, err)
+	assert.Equal(t, eci.EncodeWriteRequest(samples), decoded)
+}
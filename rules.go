@@ -0,0 +1,201 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
+)
+
+// Formats of the generated rules.
+const (
+	RulesFormatPlain = "plain"
+	RulesFormatCRD   = "crd"
+)
+
+// ErrUnknownRulesFormat is returned when the rules format is neither plain nor crd.
+var ErrUnknownRulesFormat = errors.New("unknown rules format")
+
+// RulesConfig parameterises the generated rules.
+type RulesConfig struct {
+	Initiatives []RegistrationNumber
+	Milestones  []int
+	// StaleAfter is the age of the last successful poll after which the data is considered stale.
+	StaleAfter time.Duration
+
+	// Name, Namespace and Labels are the metadata of the PrometheusRule resource.
+	Name      string
+	Namespace string
+	Labels    map[string]string
+}
+
+// RuleGroups is a Prometheus rule file.
+type RuleGroups struct {
+	Groups []RuleGroup `yaml:"groups"`
+}
+
+// RuleGroup is a group of rules evaluated together.
+type RuleGroup struct {
+	Name  string `yaml:"name"`
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule is a recording or alerting rule.
+type Rule struct {
+	Record      string            `yaml:"record,omitempty"`
+	Alert       string            `yaml:"alert,omitempty"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// PrometheusRule is the monitoring.coreos.com/v1 custom resource of the Prometheus Operator.
+type PrometheusRule struct {
+	APIVersion string             `yaml:"apiVersion"`
+	Kind       string             `yaml:"kind"`
+	Metadata   PrometheusRuleMeta `yaml:"metadata"`
+	Spec       RuleGroups         `yaml:"spec"`
+}
+
+// PrometheusRuleMeta is the metadata of a [PrometheusRule].
+type PrometheusRuleMeta struct {
+	Name      string            `yaml:"name"`
+	Namespace string            `yaml:"namespace,omitempty"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+}
+
+// Names of the generated recording rules.
+const (
+	RecordProgressRatio          = "eci:signature_progress:ratio"
+	RecordSignaturesSum          = "eci:signatures:sum"
+	RecordGoalRatio              = "eci:signature_goal_progress:ratio"
+	RecordCountriesOverThreshold = "eci:countries_over_threshold:count"
+)
+
+// milestoneWindow is how long ECIMilestoneReached fires after the milestone was passed.
+const milestoneWindow = "1h"
+
+var groupNameReplacer = regexp.MustCompile(`[^a-z0-9]+`)
+
+// GenerateRules creates one rule group per initiative with recording rules for the progress ratios and alerts for
+// stale or missing data, failing polls and reached milestones. Countries without a threshold have no progress ratio,
+// and a milestone alert only fires during the milestoneWindow after the milestone was passed.
+//
+//nolint:funlen // list of rules.
+func GenerateRules(cfg RulesConfig) RuleGroups {
+	groups := RuleGroups{Groups: make([]RuleGroup, 0, len(cfg.Initiatives))}
+
+	for _, rn := range cfg.Initiatives {
+		id := rn.String()
+		sel := fmt.Sprintf(`{initiative_id=%q}`, id)
+		summary := func(format string, a ...any) map[string]string {
+			return map[string]string{"summary": fmt.Sprintf(format, a...)}
+		}
+
+		rules := []Rule{
+			{
+				Record: RecordProgressRatio,
+				Expr:   fmt.Sprintf("%s%s / (%s%s > 0)", MetricSignatures, sel, MetricSignatureThreshold, sel),
+			},
+			{
+				Record: RecordSignaturesSum,
+				Expr:   fmt.Sprintf("sum by (initiative_id) (%s%s)", MetricSignatures, sel),
+			},
+			{
+				Record: RecordGoalRatio,
+				Expr:   fmt.Sprintf("%s%s / %d", RecordSignaturesSum, sel, TotalSignatureGoal),
+			},
+			{
+				Record: RecordCountriesOverThreshold,
+				Expr:   fmt.Sprintf("count by (initiative_id) (%s%s >= 1)", RecordProgressRatio, sel),
+			},
+			{
+				Alert: "ECIDataStale",
+				Expr: fmt.Sprintf("time() - %s%s > %d or absent(%s%s)",
+					MetricLastSuccess, sel, int(cfg.StaleAfter.Seconds()), MetricLastSuccess, sel),
+				For:         "10m",
+				Labels:      map[string]string{"severity": "warning"},
+				Annotations: summary("No successful poll of %s for more than %s", id, model.Duration(cfg.StaleAfter)),
+			},
+			{
+				Alert:       "ECIFetchFailing",
+				Expr:        fmt.Sprintf("increase(%s%s[1h]) > 2", MetricFetchFailures, sel),
+				For:         "15m",
+				Labels:      map[string]string{"severity": "warning"},
+				Annotations: summary("Polling the ECI API for %s keeps failing", id),
+			},
+			{
+				Alert: "ECIGoalReached",
+				Expr: fmt.Sprintf("%s%s >= 1 and %s%s >= %d",
+					RecordGoalRatio, sel, RecordCountriesOverThreshold, sel, MinimumCountries),
+				Labels:      map[string]string{"severity": "info"},
+				Annotations: summary("%s collected %d signatures in %d member states", id, TotalSignatureGoal, MinimumCountries),
+			},
+		}
+
+		for _, m := range cfg.Milestones {
+			rules = append(rules, Rule{
+				Alert: "ECIMilestoneReached",
+				Expr: fmt.Sprintf("%s%s >= %d and %s%s offset %s < %d",
+					RecordSignaturesSum, sel, m, RecordSignaturesSum, sel, milestoneWindow, m),
+				Labels:      map[string]string{"severity": "info", "milestone": fmt.Sprint(m)},
+				Annotations: summary("%s passed %d signatures", id, m),
+			})
+		}
+
+		groups.Groups = append(groups.Groups, RuleGroup{
+			Name:  strings.Trim(groupNameReplacer.ReplaceAllString(strings.ToLower(id), "-"), "-"),
+			Rules: rules,
+		})
+	}
+
+	return groups
+}
+
+// WriteRules writes the generated rules as YAML, either as a plain rule file or as a PrometheusRule resource.
+func WriteRules(w io.Writer, cfg RulesConfig, format string) error {
+	groups := GenerateRules(cfg)
+
+	var doc any
+
+	switch format {
+	case RulesFormatPlain:
+		doc = groups
+	case RulesFormatCRD:
+		doc = PrometheusRule{
+			APIVersion: "monitoring.coreos.com/v1",
+			Kind:       "PrometheusRule",
+			Metadata: PrometheusRuleMeta{
+				Name:      cfg.Name,
+				Namespace: cfg.Namespace,
+				Labels:    cfg.Labels,
+			},
+			Spec: groups,
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownRulesFormat, format)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2) //nolint:mnd // YAML indentation.
+
+	err := enc.Encode(doc)
+	if err != nil {
+		return fmt.Errorf("encode rules: %w", err)
+	}
+
+	err = enc.Close()
+	if err != nil {
+		return fmt.Errorf("encode rules: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	eci "github.com/tvanriel/eci-prometheus-exporter"
+	"gopkg.in/yaml.v3"
+)
+
+func rulesConfig() eci.RulesConfig {
+	return eci.RulesConfig{
+		Initiatives: []eci.RegistrationNumber{
+			*MustParseRegistrationNumber("ECI(2024)000007"),
+			*MustParseRegistrationNumber("ECI(2024)000008"),
+		},
+		Milestones: []int{100000, 1000000},
+		StaleAfter: time.Hour,
+		Name:       "eci",
+		Namespace:  "monitoring",
+	}
+}
+
+func TestGenerateRules(t *testing.T) {
+	t.Parallel()
+
+	groups := eci.GenerateRules(rulesConfig())
+
+	require.Len(t, groups.Groups, 2)
+	assert.Equal(t, "eci-2024-000007", groups.Groups[0].Name)
+
+	exprs := map[string]string{}
+	alerts := map[string][]string{}
+
+	for _, r := range groups.Groups[0].Rules {
+		if r.Record != "" {
+			exprs[r.Record] = r.Expr
+		}
+
+		if r.Alert != "" {
+			alerts[r.Alert] = append(alerts[r.Alert], r.Expr)
+			assert.Contains(t, r.Expr, `initiative_id="ECI(2024)000007"`)
+		}
+	}
+
+	assert.Equal(t,
+		`eci_signatures{initiative_id="ECI(2024)000007"} / (eci_signature_threshold{initiative_id="ECI(2024)000007"} > 0)`,
+		exprs[eci.RecordProgressRatio],
+	)
+	assert.Equal(t, `eci:signatures:sum{initiative_id="ECI(2024)000007"} / 1000000`, exprs[eci.RecordGoalRatio])
+	assert.Equal(t, []string{
+		`time() - eci_last_success_timestamp_seconds{initiative_id="ECI(2024)000007"} > 3600` +
+			` or absent(eci_last_success_timestamp_seconds{initiative_id="ECI(2024)000007"})`,
+	}, alerts["ECIDataStale"])
+	assert.Equal(t, []string{
+		`eci:signatures:sum{initiative_id="ECI(2024)000007"} >= 100000` +
+			` and eci:signatures:sum{initiative_id="ECI(2024)000007"} offset 1h < 100000`,
+		`eci:signatures:sum{initiative_id="ECI(2024)000007"} >= 1000000` +
+			` and eci:signatures:sum{initiative_id="ECI(2024)000007"} offset 1h < 1000000`,
+	}, alerts["ECIMilestoneReached"])
+	assert.Len(t, alerts, 4, "stale, failing, goal and milestones")
+}
+
+func TestWriteRules(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		format  string
+		wantErr assert.ErrorAssertionFunc
+		check   func(t *testing.T, out []byte)
+	}{
+		"plain rule file": {
+			format:  eci.RulesFormatPlain,
+			wantErr: assert.NoError,
+			check: func(t *testing.T, out []byte) {
+				t.Helper()
+
+				groups := eci.RuleGroups{}
+				require.NoError(t, yaml.Unmarshal(out, &groups))
+				assert.Equal(t, eci.GenerateRules(rulesConfig()), groups)
+			},
+		},
+		"prometheus operator resource": {
+			format:  eci.RulesFormatCRD,
+			wantErr: assert.NoError,
+			check: func(t *testing.T, out []byte) {
+				t.Helper()
+
+				rule := eci.PrometheusRule{}
+				require.NoError(t, yaml.Unmarshal(out, &rule))
+				assert.Equal(t, "PrometheusRule", rule.Kind)
+				assert.Equal(t, "monitoring", rule.Metadata.Namespace)
+				assert.Equal(t, eci.GenerateRules(rulesConfig()), rule.Spec)
+			},
+		},
+		"unknown format": {
+			format:  "json",
+			wantErr: errContains("unknown rules format"),
+			check: func(t *testing.T, out []byte) {
+				t.Helper()
+
+				assert.Empty(t, out)
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			tt.wantErr(t, eci.WriteRules(&buf, rulesConfig(), tt.format))
+			tt.check(t, buf.Bytes())
+		})
+	}
+}
@@ -0,0 +1,381 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+)
+
+// Names of the scheduler metrics.
+const (
+	MetricSchedulerQueueDepth = "eci_scheduler_queue_depth"
+	MetricSchedulerLag        = "eci_scheduler_lag_seconds"
+	MetricNextPoll            = "eci_next_poll_timestamp_seconds"
+)
+
+// Scheduler polls all initiatives from a single loop. The first polls are spread evenly over the interval, at most
+// Concurrency polls run at the same time and the polls share the request budget of Limiter.
+type Scheduler struct {
+	Interval    time.Duration
+	Concurrency int
+	Limiter     *rate.Limiter
+	// Poll polls a single initiative.
+	Poll func(ctx context.Context, registrationNumber RegistrationNumber) error
+	// Policy decides when an initiative is polled again, by default every Interval.
+	Policy PollPolicy
+
+	QueueDepth prometheus.Gauge
+	Lag        prometheus.Histogram
+	NextPoll   *prometheus.GaugeVec
+
+	mu      sync.Mutex
+	entries map[RegistrationNumber]*scheduleEntry
+	// running holds the initiatives that are being polled. It outlives removed entries, so an initiative that is
+	// removed and added again is not polled while the poll of the old entry is still running.
+	running map[RegistrationNumber]bool
+	wake    chan struct{}
+}
+
+type scheduleEntry struct {
+	next time.Time
+	// busy is set from queueing until the poll finished, so an initiative is never polled twice at the same time.
+	busy bool
+	// waiters receive the result of the next poll that is queued.
+	waiters []chan<- error
+}
+
+type scheduledPoll struct {
+	registrationNumber RegistrationNumber
+	due                time.Time
+	// entry is the entry that produced the poll. The poll is skipped when the entry was removed in the meantime.
+	entry   *scheduleEntry
+	waiters []chan<- error
+}
+
+// ErrNotScheduled is returned when an initiative is not scheduled.
+var ErrNotScheduled = errors.New("initiative is not scheduled")
+
+// NewScheduler creates a [Scheduler] for the initiatives. A limit of [rate.Inf] disables the rate limit.
+func NewScheduler(
+	initiatives []RegistrationNumber,
+	interval time.Duration,
+	concurrency int,
+	limit rate.Limit,
+	poll func(ctx context.Context, registrationNumber RegistrationNumber) error,
+) *Scheduler {
+	s := &Scheduler{
+		Interval:    interval,
+		Concurrency: max(concurrency, 1),
+		Limiter:     rate.NewLimiter(limit, 1),
+		Poll:        poll,
+
+		QueueDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: MetricSchedulerQueueDepth,
+			Help: "Number of polls that are due but wait for a free worker",
+		}),
+		Lag: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    MetricSchedulerLag,
+			Help:    "Delay between the scheduled and the actual start of a poll",
+			Buckets: []float64{0.01, 0.1, 0.5, 1, 5, 10, 30, 60, 300},
+		}),
+		NextPoll: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: MetricNextPoll,
+			Help: "Unix timestamp of the next scheduled poll per initiative",
+		}, []string{"initiative_id"}),
+
+		entries: map[RegistrationNumber]*scheduleEntry{},
+		running: map[RegistrationNumber]bool{},
+		wake:    make(chan struct{}, 1),
+	}
+
+	now := time.Now()
+
+	for i, rn := range initiatives {
+		s.schedule(rn, &scheduleEntry{}, now.Add(interval*time.Duration(i)/time.Duration(len(initiatives))))
+	}
+
+	return s
+}
+
+// MustRegisterWith registers the scheduler metrics with the given prometheus registerer.
+func (s *Scheduler) MustRegisterWith(r prometheus.Registerer) {
+	r.MustRegister(s.QueueDepth, s.Lag, s.NextPoll)
+}
+
+// schedule sets the next poll of the entry. The caller must hold the lock or own the scheduler.
+func (s *Scheduler) schedule(registrationNumber RegistrationNumber, e *scheduleEntry, next time.Time) {
+	e.next = next
+	s.entries[registrationNumber] = e
+	s.NextPoll.WithLabelValues(registrationNumber.String()).Set(float64(next.UnixMilli()) / 1000) //nolint:mnd // ms.
+}
+
+// Add schedules the initiative, which is polled right away.
+func (s *Scheduler) Add(registrationNumber RegistrationNumber) {
+	s.mu.Lock()
+	if _, ok := s.entries[registrationNumber]; !ok {
+		s.schedule(registrationNumber, &scheduleEntry{}, time.Now())
+	}
+	s.mu.Unlock()
+
+	s.signal()
+}
+
+// Remove stops polling the initiative. A running poll is not interrupted.
+func (s *Scheduler) Remove(registrationNumber RegistrationNumber) {
+	s.mu.Lock()
+	if e, ok := s.entries[registrationNumber]; ok {
+		notify(e.waiters, ErrNotScheduled)
+	}
+
+	delete(s.entries, registrationNumber)
+	s.NextPoll.DeleteLabelValues(registrationNumber.String())
+	s.mu.Unlock()
+
+	s.signal()
+}
+
+// Trigger moves the next poll of the initiative to now. It returns false when the initiative is not scheduled.
+func (s *Scheduler) Trigger(registrationNumber RegistrationNumber) bool {
+	s.mu.Lock()
+	e, ok := s.entries[registrationNumber]
+
+	if ok && !e.busy {
+		s.schedule(registrationNumber, e, time.Now())
+	}
+	s.mu.Unlock()
+
+	s.signal()
+
+	return ok
+}
+
+// PollNow polls the initiative as soon as the scheduler allows it and returns the result of the poll. A running poll
+// of the initiative is finished first, concurrent calls share one poll, and the next scheduled poll is an interval
+// after it.
+func (s *Scheduler) PollNow(ctx context.Context, registrationNumber RegistrationNumber) error {
+	result := make(chan error, 1)
+
+	s.mu.Lock()
+	e, ok := s.entries[registrationNumber]
+
+	if ok {
+		e.waiters = append(e.waiters, result)
+
+		if !e.busy {
+			s.schedule(registrationNumber, e, time.Now())
+		}
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return ErrNotScheduled
+	}
+
+	s.signal()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck // reported as is.
+	}
+}
+
+// notify sends the result of a poll to the waiters.
+func notify(waiters []chan<- error, err error) {
+	for _, w := range waiters {
+		w <- err
+	}
+}
+
+// Initiatives returns the scheduled initiatives.
+func (s *Scheduler) Initiatives() []RegistrationNumber {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]RegistrationNumber, 0, len(s.entries))
+	for rn := range s.entries {
+		result = append(result, rn)
+	}
+
+	return result
+}
+
+func (s *Scheduler) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run polls the initiatives until the context is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	jobs := make(chan scheduledPoll)
+
+	var wg sync.WaitGroup
+
+	for range s.Concurrency {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			s.work(ctx, jobs)
+		}()
+	}
+
+	defer wg.Wait()
+	defer close(jobs)
+
+	var queue []scheduledPoll
+
+	for {
+		queue = append(queue, s.due(time.Now())...)
+		s.QueueDepth.Set(float64(len(queue)))
+
+		var (
+			send chan<- scheduledPoll
+			head scheduledPoll
+		)
+
+		if len(queue) > 0 {
+			send = jobs
+			head = queue[0]
+		}
+
+		timer := time.NewTimer(s.untilNext(time.Now()))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return
+		case send <- head:
+			queue = queue[1:]
+		case <-s.wake:
+		case <-timer.C:
+		}
+
+		timer.Stop()
+	}
+}
+
+// due marks the initiatives whose poll is due as busy and returns them in order of their due time.
+func (s *Scheduler) due(now time.Time) []scheduledPoll {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var polls []scheduledPoll
+
+	for rn, e := range s.entries {
+		if e.busy || s.running[rn] || e.next.After(now) {
+			continue
+		}
+
+		e.busy = true
+
+		polls = append(polls, scheduledPoll{registrationNumber: rn, due: e.next, entry: e, waiters: e.waiters})
+		e.waiters = nil
+	}
+
+	sort.Slice(polls, func(i, j int) bool { return polls[i].due.Before(polls[j].due) })
+
+	return polls
+}
+
+// untilNext returns the time until the next idle initiative is due.
+func (s *Scheduler) untilNext(now time.Time) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wait := s.Interval
+
+	for rn, e := range s.entries {
+		if !e.busy && !s.running[rn] {
+			wait = min(wait, e.next.Sub(now))
+		}
+	}
+
+	return max(wait, 0)
+}
+
+func (s *Scheduler) work(ctx context.Context, jobs <-chan scheduledPoll) {
+	for job := range jobs {
+		err := s.Limiter.Wait(ctx)
+		if err != nil {
+			notify(job.waiters, err)
+
+			return
+		}
+
+		if !s.start(job) {
+			continue
+		}
+
+		s.Lag.Observe(time.Since(job.due).Seconds())
+		err = s.Poll(ctx, job.registrationNumber)
+
+		s.finish(job, s.next(job))
+		notify(job.waiters, err)
+	}
+}
+
+// start marks the initiative of the job as running. It returns false when the entry of the job was removed.
+func (s *Scheduler) start(job scheduledPoll) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.entries[job.registrationNumber] != job.entry {
+		job.entry.busy = false
+		notify(job.waiters, ErrNotScheduled)
+
+		return false
+	}
+
+	s.running[job.registrationNumber] = true
+
+	return true
+}
+
+// finish clears the entry of the job and schedules its next poll, unless the entry was removed during the poll.
+func (s *Scheduler) finish(job scheduledPoll, next time.Time) {
+	s.mu.Lock()
+	job.entry.busy = false
+	delete(s.running, job.registrationNumber)
+
+	if s.entries[job.registrationNumber] == job.entry {
+		if len(job.entry.waiters) > 0 {
+			// PollNow was called during the poll.
+			next = time.Now()
+		}
+
+		s.schedule(job.registrationNumber, job.entry, next)
+	}
+	s.mu.Unlock()
+
+	s.signal()
+}
+
+// next returns the time of the poll after the given one.
+func (s *Scheduler) next(job scheduledPoll) time.Time {
+	now := time.Now()
+
+	if s.Policy != nil {
+		return s.Policy.Next(job.registrationNumber, now)
+	}
+
+	// Keep the polls spread over the interval, unless the poll was delayed by more than an interval.
+	next := job.due.Add(s.Interval)
+	if next.Before(now) {
+		next = now.Add(s.Interval)
+	}
+
+	return next
+}
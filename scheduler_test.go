@@ -0,0 +1,314 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	eci "github.com/tvanriel/eci-prometheus-exporter"
+	"golang.org/x/time/rate"
+)
+
+// pollRecorder records the first poll of every initiative and the maximum number of concurrent polls.
+type pollRecorder struct {
+	delay time.Duration
+
+	mu     sync.Mutex
+	first  map[string]time.Time
+	polls  int
+	active atomic.Int32
+	peak   atomic.Int32
+}
+
+func (p *pollRecorder) poll(_ context.Context, rn eci.RegistrationNumber) error {
+	active := p.active.Add(1)
+	defer p.active.Add(-1)
+
+	for {
+		peak := p.peak.Load()
+		if active <= peak || p.peak.CompareAndSwap(peak, active) {
+			break
+		}
+	}
+
+	p.mu.Lock()
+	if _, ok := p.first[rn.String()]; !ok {
+		p.first[rn.String()] = time.Now()
+	}
+	p.polls++
+	p.mu.Unlock()
+
+	time.Sleep(p.delay)
+
+	return nil
+}
+
+func (p *pollRecorder) polled() (int, map[string]time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	first := make(map[string]time.Time, len(p.first))
+	for k, v := range p.first {
+		first[k] = v
+	}
+
+	return p.polls, first
+}
+
+func initiatives(n int) []eci.RegistrationNumber {
+	result := make([]eci.RegistrationNumber, 0, n)
+	for i := range n {
+		result = append(result, *MustParseRegistrationNumber(fmt.Sprintf("ECI(2024)%06d", i+1)))
+	}
+
+	return result
+}
+
+func TestScheduler_Run(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		initiatives int
+		interval    time.Duration
+		concurrency int
+		limit       rate.Limit
+		// add adds the initiatives after the start instead of passing them to the constructor.
+		add   bool
+		check func(t *testing.T, start time.Time, r *pollRecorder, ids []eci.RegistrationNumber)
+	}{
+		"staggers the first polls": {
+			initiatives: 3,
+			interval:    300 * time.Millisecond,
+			concurrency: 3,
+			limit:       rate.Inf,
+			check: func(t *testing.T, start time.Time, r *pollRecorder, ids []eci.RegistrationNumber) {
+				t.Helper()
+
+				_, first := r.polled()
+				assert.Less(t, first[ids[0].String()].Sub(start), 80*time.Millisecond)
+				assert.GreaterOrEqual(t, first[ids[1].String()].Sub(start), 90*time.Millisecond)
+				assert.GreaterOrEqual(t, first[ids[2].String()].Sub(start), 190*time.Millisecond)
+			},
+		},
+		"caps concurrent polls": {
+			initiatives: 6,
+			interval:    time.Hour,
+			concurrency: 2,
+			limit:       rate.Inf,
+			add:         true,
+			check: func(t *testing.T, _ time.Time, r *pollRecorder, _ []eci.RegistrationNumber) {
+				t.Helper()
+
+				assert.LessOrEqual(t, r.peak.Load(), int32(2))
+			},
+		},
+		"enforces the rate limit": {
+			initiatives: 5,
+			interval:    time.Hour,
+			concurrency: 5,
+			limit:       20,
+			add:         true,
+			check: func(t *testing.T, start time.Time, r *pollRecorder, ids []eci.RegistrationNumber) {
+				t.Helper()
+
+				_, first := r.polled()
+				last := start
+
+				for _, rn := range ids {
+					if first[rn.String()].After(last) {
+						last = first[rn.String()]
+					}
+				}
+
+				assert.GreaterOrEqual(t, last.Sub(start), 150*time.Millisecond, "5 polls at 20/s with a burst of 1")
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ids := initiatives(tt.initiatives)
+			recorder := &pollRecorder{delay: 20 * time.Millisecond, first: map[string]time.Time{}}
+
+			initial := ids
+			if tt.add {
+				initial = nil
+			}
+
+			start := time.Now()
+			s := eci.NewScheduler(initial, tt.interval, tt.concurrency, tt.limit, recorder.poll)
+
+			ctx, cancel := context.WithCancel(t.Context())
+			defer cancel()
+
+			go s.Run(ctx)
+
+			if tt.add {
+				for _, rn := range ids {
+					s.Add(rn)
+				}
+			}
+
+			assert.EventuallyWithT(t, func(c *assert.CollectT) {
+				_, first := recorder.polled()
+				assert.Len(c, first, tt.initiatives)
+			}, 2*time.Second, 5*time.Millisecond)
+
+			tt.check(t, start, recorder, ids)
+		})
+	}
+}
+
+func TestScheduler_TriggerAndRemove(t *testing.T) {
+	t.Parallel()
+
+	ids := initiatives(2)
+	recorder := &pollRecorder{first: map[string]time.Time{}}
+	s := eci.NewScheduler(ids[:1], time.Hour, 1, rate.Inf, recorder.poll)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	go s.Run(ctx)
+
+	waitPolls := func(n int) {
+		assert.EventuallyWithT(t, func(c *assert.CollectT) {
+			polls, _ := recorder.polled()
+			assert.Equal(c, n, polls)
+		}, time.Second, 5*time.Millisecond)
+	}
+
+	waitPolls(1)
+
+	assert.True(t, s.Trigger(ids[0]))
+	waitPolls(2)
+
+	assert.False(t, s.Trigger(ids[1]), "not scheduled")
+
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		next := testutil.ToFloat64(s.NextPoll.WithLabelValues(ids[0].String()))
+		assert.InDelta(c, float64(time.Now().Add(time.Hour).Unix()), next, 5)
+	}, time.Second, 5*time.Millisecond)
+
+	s.Remove(ids[0])
+	require.Empty(t, s.Initiatives())
+	assert.False(t, s.Trigger(ids[0]))
+
+	time.Sleep(50 * time.Millisecond)
+
+	polls, _ := recorder.polled()
+	assert.Equal(t, 2, polls)
+}
+
+// blockingPoller blocks the first poll of an initiative until release is closed and detects overlapping polls.
+type blockingPoller struct {
+	release chan struct{}
+
+	mu      sync.Mutex
+	polls   map[string]int
+	active  map[string]int
+	overlap bool
+}
+
+func (p *blockingPoller) poll(_ context.Context, rn eci.RegistrationNumber) error {
+	p.mu.Lock()
+	p.polls[rn.String()]++
+	p.active[rn.String()]++
+	p.overlap = p.overlap || p.active[rn.String()] > 1
+	first := p.polls[rn.String()] == 1
+	p.mu.Unlock()
+
+	if first {
+		<-p.release
+	}
+
+	p.mu.Lock()
+	p.active[rn.String()]--
+	p.mu.Unlock()
+
+	return nil
+}
+
+func (p *blockingPoller) count(rn eci.RegistrationNumber) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.polls[rn.String()]
+}
+
+func TestScheduler_RemoveDuringPoll(t *testing.T) {
+	t.Parallel()
+
+	ids := initiatives(2)
+
+	tests := map[string]struct {
+		concurrency int
+		change      func(s *eci.Scheduler)
+		want        map[eci.RegistrationNumber]int
+	}{
+		"added again while polled": {
+			concurrency: 2,
+			change: func(s *eci.Scheduler) {
+				s.Remove(ids[0])
+				s.Add(ids[0])
+			},
+			want: map[eci.RegistrationNumber]int{ids[0]: 2},
+		},
+		"removed while queued": {
+			concurrency: 1,
+			change: func(s *eci.Scheduler) {
+				s.Add(ids[1])
+				time.Sleep(20 * time.Millisecond) // let the scheduler queue the poll.
+				s.Remove(ids[1])
+			},
+			want: map[eci.RegistrationNumber]int{ids[0]: 1, ids[1]: 0},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p := &blockingPoller{release: make(chan struct{}), polls: map[string]int{}, active: map[string]int{}}
+			s := eci.NewScheduler(ids[:1], time.Hour, tt.concurrency, rate.Inf, p.poll)
+
+			ctx, cancel := context.WithCancel(t.Context())
+			defer cancel()
+
+			go s.Run(ctx)
+
+			assert.Eventually(t, func() bool { return p.count(ids[0]) == 1 }, time.Second, 5*time.Millisecond)
+
+			tt.change(s)
+			time.Sleep(50 * time.Millisecond)
+			assert.Equal(t, 1, p.count(ids[0]), "not polled while the old poll runs")
+
+			close(p.release)
+
+			assert.EventuallyWithT(t, func(c *assert.CollectT) {
+				for rn, want := range tt.want {
+					assert.Equal(c, want, p.count(rn), rn.String())
+				}
+			}, time.Second, 5*time.Millisecond)
+
+			time.Sleep(50 * time.Millisecond)
+
+			for rn, want := range tt.want {
+				assert.Equal(t, want, p.count(rn), rn.String())
+			}
+
+			p.mu.Lock()
+			defer p.mu.Unlock()
+
+			assert.False(t, p.overlap, "overlapping polls of one initiative")
+		})
+	}
+}
@@ -0,0 +1,183 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Sink receives the parsed report after every successful [Application.FetchAndUpdateMetrics].
+type Sink interface {
+	Write(ctx context.Context, registrationNumber RegistrationNumber, report *ProgressResponse) error
+}
+
+// Sample is a single value of a time series that is written to a time-series database.
+type Sample struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels"`
+	Value  float64           `json:"value"`
+	Time   time.Time         `json:"time"`
+}
+
+// SampleWriter writes a batch of samples to a time-series database.
+type SampleWriter interface {
+	WriteSamples(ctx context.Context, samples []Sample) error
+}
+
+// ReportSamples converts a report into samples with the same names and labels as the Prometheus metrics.
+func ReportSamples(registrationNumber RegistrationNumber, report *ProgressResponse, at time.Time) []Sample {
+	initiativeID := registrationNumber.String()
+
+	var th Threshold
+
+	registrationDate, err := time.Parse("02/01/2006", report.RegistrationDate)
+	if err == nil {
+		th = GetThresholds(registrationDate)
+	}
+
+	samples := make([]Sample, 0, 2*len(report.SOSReport.Entries)) //nolint:mnd // signatures and thresholds.
+
+	for _, e := range report.SOSReport.Entries {
+		labels := map[string]string{"initiative_id": initiativeID, "country_code": e.CountryCode}
+
+		samples = append(samples, Sample{Name: MetricSignatures, Labels: labels, Value: float64(e.Total), Time: at})
+
+		if goal, ok := th[Countries.MemberCountryCode(e.CountryCode)]; ok {
+			samples = append(samples, Sample{Name: MetricSignatureThreshold, Labels: labels, Value: float64(goal), Time: at})
+		}
+	}
+
+	return samples
+}
+
+// BatchConfig configures the batching and retrying of a [BatchSink].
+type BatchConfig struct {
+	BatchSize     int
+	FlushInterval time.Duration
+	Retries       int
+	RetryBackoff  time.Duration
+}
+
+// ErrInvalidBatchConfig is returned when a [BatchConfig] cannot be used.
+var ErrInvalidBatchConfig = errors.New("invalid sink batch config")
+
+// Validate checks that the flush interval is positive.
+func (c BatchConfig) Validate() error {
+	if c.FlushInterval <= 0 {
+		return fmt.Errorf("%w: flush interval %s is not positive", ErrInvalidBatchConfig, c.FlushInterval)
+	}
+
+	return nil
+}
+
+// BatchSink is a [Sink] that buffers samples and writes them in batches, retrying failed writes.
+type BatchSink struct {
+	Name   string
+	Writer SampleWriter
+	Config BatchConfig
+	Logger *zap.Logger
+
+	mu     sync.Mutex
+	buffer []Sample
+	full   chan struct{} // signals Run to flush a full batch
+}
+
+// NewBatchSink creates a [BatchSink] for the given writer.
+func NewBatchSink(name string, writer SampleWriter, cfg BatchConfig, logger *zap.Logger) *BatchSink {
+	return &BatchSink{
+		Name:   name,
+		Writer: writer,
+		Config: cfg,
+		Logger: logger.With(zap.String("sink", name)),
+		full:   make(chan struct{}, 1),
+	}
+}
+
+// Write implements [Sink]. Once the batch is full, [BatchSink.Run] is signalled to flush it, so the poll does not wait
+// for the write and its retries.
+func (b *BatchSink) Write(_ context.Context, registrationNumber RegistrationNumber, report *ProgressResponse) error {
+	b.mu.Lock()
+	b.buffer = append(b.buffer, ReportSamples(registrationNumber, report, time.Now())...)
+	full := len(b.buffer) >= b.Config.BatchSize
+	b.mu.Unlock()
+
+	if full {
+		select {
+		case b.full <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Run flushes the buffer every flush interval and when the batch is full, until the context is cancelled.
+func (b *BatchSink) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.Config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = b.Flush(ctx)
+		case <-b.full:
+			_ = b.Flush(ctx)
+		}
+	}
+}
+
+// Flush writes all buffered samples. The batch is dropped when all retries failed.
+func (b *BatchSink) Flush(ctx context.Context) error {
+	b.mu.Lock()
+	samples := b.buffer
+	b.buffer = nil
+	b.mu.Unlock()
+
+	if len(samples) == 0 {
+		return nil
+	}
+
+	var err error
+
+	for attempt := 0; attempt <= b.Config.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("flush %s sink: %w", b.Name, ctx.Err())
+			case <-time.After(b.Config.RetryBackoff * time.Duration(attempt)):
+			}
+		}
+
+		err = b.Writer.WriteSamples(ctx, samples)
+		if err == nil {
+			return nil
+		}
+
+		b.Logger.Warn("Cannot write samples", zap.Int("attempt", attempt+1), zap.Error(err))
+	}
+
+	b.Logger.Error("Dropping samples after failed retries", zap.Int("samples", len(samples)), zap.Error(err))
+
+	return fmt.Errorf("flush %s sink: %w", b.Name, err)
+}
+
+// notifySinks hands the report to every configured sink.
+func (a *Application) notifySinks(ctx context.Context, registrationNumber RegistrationNumber, report *ProgressResponse) {
+	for _, s := range a.Sinks {
+		err := s.Write(ctx, registrationNumber, report)
+		if err != nil {
+			a.Logger.Error("Sink failed",
+				zap.String("initiative_id", registrationNumber.String()),
+				zap.Error(err),
+			)
+		}
+	}
+}
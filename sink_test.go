@@ -0,0 +1,249 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	eci "github.com/tvanriel/eci-prometheus-exporter"
+	"go.uber.org/zap/zaptest"
+)
+
+var errWriteFailed = errors.New("write failed")
+
+// flakyWriter fails the first failures writes and records the successful batches.
+type flakyWriter struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+	batches  [][]eci.Sample
+}
+
+func (w *flakyWriter) WriteSamples(_ context.Context, samples []eci.Sample) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.calls++
+	if w.calls <= w.failures {
+		return errWriteFailed
+	}
+
+	w.batches = append(w.batches, samples)
+
+	return nil
+}
+
+func testReport(t *testing.T) *eci.ProgressResponse {
+	t.Helper()
+
+	report := &eci.ProgressResponse{}
+	require.NoError(t, json.Unmarshal([]byte(defaultResponse), report))
+
+	return report
+}
+
+func TestReportSamples(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2025, 6, 4, 0, 0, 0, 0, time.UTC)
+	samples := eci.ReportSamples(*MustParseRegistrationNumber("ECI(2024)000007"), testReport(t), at)
+
+	require.Len(t, samples, 2*27)
+
+	for _, sample := range samples {
+		assert.Contains(t, []string{eci.MetricSignatures, eci.MetricSignatureThreshold}, sample.Name)
+	}
+
+	assert.Contains(t, samples, eci.Sample{
+		Name:   "eci_signature_threshold",
+		Labels: map[string]string{"initiative_id": "ECI(2024)000007", "country_code": "NL"},
+		Value:  20445,
+		Time:   at,
+	})
+}
+
+func TestBatchConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		interval time.Duration
+		wantErr  assert.ErrorAssertionFunc
+	}{
+		"positive": {interval: time.Second, wantErr: assert.NoError},
+		"zero":     {interval: 0, wantErr: errContains("invalid sink batch config")},
+		"negative": {interval: -time.Second, wantErr: errContains("invalid sink batch config")},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.wantErr(t, eci.BatchConfig{BatchSize: 1, FlushInterval: tt.interval}.Validate())
+		})
+	}
+}
+
+func TestBatchSink_Write(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		failures    int
+		retries     int
+		batchSize   int
+		wantCalls   int
+		wantBatches int
+	}{
+		"buffers below batch size": {
+			batchSize:   1000,
+			wantCalls:   0,
+			wantBatches: 0,
+		},
+		"flushes full batch": {
+			batchSize:   10,
+			wantCalls:   1,
+			wantBatches: 1,
+		},
+		"retries failed writes": {
+			failures:    2,
+			retries:     2,
+			batchSize:   10,
+			wantCalls:   3,
+			wantBatches: 1,
+		},
+		"drops batch after retries": {
+			failures:    3,
+			retries:     2,
+			batchSize:   10,
+			wantCalls:   3,
+			wantBatches: 0,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			writer := &flakyWriter{failures: tt.failures}
+			sink := eci.NewBatchSink("test", writer, eci.BatchConfig{
+				BatchSize:     tt.batchSize,
+				FlushInterval: time.Hour,
+				Retries:       tt.retries,
+				RetryBackoff:  time.Millisecond,
+			}, zaptest.NewLogger(t))
+
+			go sink.Run(t.Context())
+
+			require.NoError(t, sink.Write(t.Context(), *MustParseRegistrationNumber("ECI(2024)000007"), testReport(t)))
+
+			assert.EventuallyWithT(t, func(c *assert.CollectT) {
+				writer.mu.Lock()
+				defer writer.mu.Unlock()
+
+				assert.Equal(c, tt.wantCalls, writer.calls)
+				assert.Len(c, writer.batches, tt.wantBatches)
+			}, time.Second, 5*time.Millisecond)
+		})
+	}
+}
+
+// blockingWriter blocks every write until it is released.
+type blockingWriter struct {
+	release chan struct{}
+}
+
+func (w *blockingWriter) WriteSamples(ctx context.Context, _ []eci.Sample) error {
+	select {
+	case <-w.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestBatchSink_WriteDoesNotWaitForFlush(t *testing.T) {
+	t.Parallel()
+
+	writer := &blockingWriter{release: make(chan struct{})}
+	defer close(writer.release)
+
+	sink := eci.NewBatchSink("test", writer, eci.BatchConfig{BatchSize: 1, FlushInterval: time.Hour}, zaptest.NewLogger(t))
+
+	go sink.Run(t.Context())
+
+	for range 3 {
+		require.NoError(t, sink.Write(t.Context(), *MustParseRegistrationNumber("ECI(2024)000007"), testReport(t)))
+	}
+}
+
+func TestBatchSink_Flush(t *testing.T) {
+	t.Parallel()
+
+	writer := &flakyWriter{}
+	sink := eci.NewBatchSink("test", writer, eci.BatchConfig{BatchSize: 1000, FlushInterval: time.Hour}, zaptest.NewLogger(t))
+
+	require.NoError(t, sink.Write(t.Context(), *MustParseRegistrationNumber("ECI(2024)000007"), testReport(t)))
+	require.NoError(t, sink.Write(t.Context(), *MustParseRegistrationNumber("ECI(2024)000008"), testReport(t)))
+	require.NoError(t, sink.Flush(t.Context()))
+	require.NoError(t, sink.Flush(t.Context()))
+
+	require.Len(t, writer.batches, 1)
+	assert.Len(t, writer.batches[0], 2*2*27)
+}
+
+func TestApplication_FetchAndUpdateMetricsNotifiesSinks(t *testing.T) {
+	t.Parallel()
+
+	server := ServerWantsCallForInitiativeID(MustParseRegistrationNumber("ECI(2024)000007"))(t)
+	defer server.Close()
+
+	writer := &flakyWriter{}
+	app := eci.NewApplication(zaptest.NewLogger(t), server.URL, nil, "", http.DefaultClient)
+	sink := eci.NewBatchSink("test", writer, eci.BatchConfig{BatchSize: 1, FlushInterval: time.Hour}, zaptest.NewLogger(t))
+	app.Sinks = []eci.Sink{sink}
+
+	go sink.Run(t.Context())
+
+	require.NoError(t, app.FetchAndUpdateMetrics(t.Context(), *MustParseRegistrationNumber("ECI(2024)000007")))
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		writer.mu.Lock()
+		defer writer.mu.Unlock()
+
+		assert.Len(c, writer.batches, 1)
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestNDJSONWriter_WriteSamples(t *testing.T) {
+	t.Parallel()
+
+	writer := &eci.NDJSONWriter{Path: filepath.Join(t.TempDir(), "samples.ndjson")}
+	samples := eci.ReportSamples(*MustParseRegistrationNumber("ECI(2024)000007"), testReport(t), time.Now())
+
+	require.NoError(t, writer.WriteSamples(t.Context(), samples))
+	require.NoError(t, writer.WriteSamples(t.Context(), samples))
+
+	f, err := os.Open(writer.Path)
+	require.NoError(t, err)
+
+	defer f.Close() //nolint:errcheck // test.
+
+	lines := 0
+	scanner := bufio.NewScanner(f)
+
+	for scanner.Scan() {
+		s := eci.Sample{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &s))
+
+		lines++
+	}
+
+	assert.Equal(t, 2*len(samples), lines)
+}
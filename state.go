@@ -0,0 +1,237 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// State is the exporter state that survives restarts. It is kept in memory when Path is empty.
+type State struct {
+	Path string
+
+	mu   sync.Mutex
+	data stateData
+}
+
+type stateData struct {
+	// Events are the keys of the delivered events with the time they were delivered.
+	Events map[string]time.Time `json:"events"`
+	// Deliveries are the webhooks that accepted an event that has not been delivered to every webhook yet, by
+	// event key and webhook URL.
+	Deliveries map[string]map[string]time.Time `json:"deliveries,omitempty"`
+	// Seen are the initiatives for which a report has been processed.
+	Seen map[string]bool `json:"seen"`
+	// Initiatives are the initiatives added and removed through the admin API.
+	Initiatives initiativeChanges `json:"initiatives"`
+}
+
+type initiativeChanges struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// LoadState reads the state from the given file. A missing file results in an empty state.
+func LoadState(path string) (*State, error) {
+	s := &State{
+		Path: path,
+		data: stateData{Events: map[string]time.Time{}, Seen: map[string]bool{}},
+	}
+
+	if path == "" {
+		return s, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("read state: %w", err)
+	}
+
+	err = json.Unmarshal(content, &s.data)
+	if err != nil {
+		return nil, fmt.Errorf("decode state: %w", err)
+	}
+
+	if s.data.Events == nil {
+		s.data.Events = map[string]time.Time{}
+	}
+
+	if s.data.Seen == nil {
+		s.data.Seen = map[string]bool{}
+	}
+
+	return s, nil
+}
+
+// Fired reports whether the event with the given key has already been delivered.
+func (s *State) Fired(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.data.Events[key]
+
+	return ok
+}
+
+// MarkFired records the event as delivered to every webhook.
+func (s *State) MarkFired(key string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data.Events[key] = at
+	delete(s.data.Deliveries, key)
+
+	return s.save()
+}
+
+// Delivered reports whether the webhook with the given URL accepted the event with the given key.
+func (s *State) Delivered(key, url string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.data.Deliveries[key][url]
+
+	return ok
+}
+
+// MarkDelivered records that the webhook with the given URL accepted the event.
+func (s *State) MarkDelivered(key, url string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.data.Deliveries == nil {
+		s.data.Deliveries = map[string]map[string]time.Time{}
+	}
+
+	if s.data.Deliveries[key] == nil {
+		s.data.Deliveries[key] = map[string]time.Time{}
+	}
+
+	s.data.Deliveries[key][url] = at
+
+	return s.save()
+}
+
+// Seen reports whether a report for the initiative has been processed before.
+func (s *State) Seen(initiativeID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.data.Seen[initiativeID]
+}
+
+// MarkSeen records that a report for the initiative has been processed.
+func (s *State) MarkSeen(initiativeID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data.Seen[initiativeID] = true
+
+	return s.save()
+}
+
+// Initiatives applies the initiatives added and removed through the admin API to the configured initiatives.
+func (s *State) Initiatives(configured []RegistrationNumber) ([]RegistrationNumber, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]RegistrationNumber, 0, len(configured)+len(s.data.Initiatives.Added))
+
+	for _, rn := range configured {
+		if !slices.Contains(s.data.Initiatives.Removed, rn.String()) {
+			result = append(result, rn)
+		}
+	}
+
+	for _, id := range s.data.Initiatives.Added {
+		rn, err := ParseRegistrationNumber(id)
+		if err != nil {
+			return nil, fmt.Errorf("parse added initiative %q: %w", id, err)
+		}
+
+		if !slices.Contains(result, *rn) {
+			result = append(result, *rn)
+		}
+	}
+
+	return result, nil
+}
+
+// AddInitiative records that the initiative was added through the admin API.
+func (s *State) AddInitiative(initiativeID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changes := &s.data.Initiatives
+	changes.Removed = slices.DeleteFunc(changes.Removed, func(id string) bool { return id == initiativeID })
+
+	if !slices.Contains(changes.Added, initiativeID) {
+		changes.Added = append(changes.Added, initiativeID)
+	}
+
+	return s.save()
+}
+
+// RemoveInitiative records that the initiative was removed through the admin API.
+func (s *State) RemoveInitiative(initiativeID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changes := &s.data.Initiatives
+	changes.Added = slices.DeleteFunc(changes.Added, func(id string) bool { return id == initiativeID })
+
+	if !slices.Contains(changes.Removed, initiativeID) {
+		changes.Removed = append(changes.Removed, initiativeID)
+	}
+
+	return s.save()
+}
+
+// save atomically replaces the state file. The caller must hold the lock.
+func (s *State) save() error {
+	if s.Path == "" {
+		return nil
+	}
+
+	content, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path))
+	if err != nil {
+		return fmt.Errorf("write state: %w", err)
+	}
+
+	defer os.Remove(tmp.Name()) //nolint:errcheck // already renamed on success.
+
+	_, err = tmp.Write(content)
+	if err != nil {
+		_ = tmp.Close()
+
+		return fmt.Errorf("write state: %w", err)
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		return fmt.Errorf("write state: %w", err)
+	}
+
+	err = os.Rename(tmp.Name(), s.Path)
+	if err != nil {
+		return fmt.Errorf("write state: %w", err)
+	}
+
+	return nil
+}
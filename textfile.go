@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TextfileName is the name of the file written into the textfile directory.
+const TextfileName = "eci_exporter.prom"
+
+// Textfile writes the gathered metrics into a file for the node_exporter textfile collector.
+type Textfile struct {
+	Path     string
+	Gatherer prometheus.Gatherer
+
+	mu sync.Mutex
+}
+
+// NewTextfile creates a [Textfile] that writes into the given directory.
+func NewTextfile(directory string, gatherer prometheus.Gatherer) *Textfile {
+	return &Textfile{
+		Path:     filepath.Join(directory, TextfileName),
+		Gatherer: gatherer,
+	}
+}
+
+// Write atomically replaces the textfile with the currently gathered metrics.
+func (t *Textfile) Write() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	err := prometheus.WriteToTextfile(t.Path, t.Gatherer)
+	if err != nil {
+		return fmt.Errorf("write textfile: %w", err)
+	}
+
+	return nil
+}
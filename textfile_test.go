@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main_test
+
+import (
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	eci "github.com/tvanriel/eci-prometheus-exporter"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestTextfile_Write(t *testing.T) {
+	t.Parallel()
+
+	server := ServerWantsCallForInitiativeID(MustParseRegistrationNumber("ECI(2024)000007"))(t)
+	defer server.Close()
+
+	registry := prometheus.NewRegistry()
+	app := eci.NewApplication(
+		zaptest.NewLogger(t),
+		server.URL,
+		[]eci.RegistrationNumber{*MustParseRegistrationNumber("ECI(2024)000007")},
+		"",
+		http.DefaultClient,
+	)
+	app.MustRegisterWith(registry)
+
+	require.NoError(t, app.FetchAndUpdateMetrics(t.Context(), *MustParseRegistrationNumber("ECI(2024)000007")))
+
+	textfile := eci.NewTextfile(t.TempDir(), registry)
+	require.NoError(t, textfile.Write())
+
+	content, err := os.ReadFile(textfile.Path)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(content), `eci_signatures{country_code="NL",initiative_id="ECI(2024)000007"} 75811`)
+	assert.Contains(t, string(content), `eci_signature_threshold{country_code="NL",initiative_id="ECI(2024)000007"} 20445`)
+	assert.NotContains(t, string(content), "go_goroutines")
+}
+
+func TestTextfile_WriteMissingDirectory(t *testing.T) {
+	t.Parallel()
+
+	textfile := eci.NewTextfile(t.TempDir()+"/does-not-exist", prometheus.NewRegistry())
+
+	require.ErrorContains(t, textfile.Write(), "write textfile")
+}
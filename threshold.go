@@ -174,3 +174,6 @@ func GetThresholds(registrationDate time.Time) Threshold {
 		return nil
 	}
 }
+
+// TotalSignatureGoal is the number of signatures an initiative needs in total.
+const TotalSignatureGoal = 1_000_000
@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// TracerName is the name of the tracer used for the spans around the ECI API calls.
+const TracerName = "github.com/tvanriel/eci-prometheus-exporter"
+
+// NewOTLPTracerProvider creates a tracer provider that exports spans via OTLP.
+func NewOTLPTracerProvider(
+	ctx context.Context,
+	cfg OTLPConfig,
+	initiatives []RegistrationNumber,
+) (*sdktrace.TracerProvider, error) {
+	exporter, err := newOTLPTraceExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := newResource(initiatives)
+	if err != nil {
+		return nil, err
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	), nil
+}
+
+func newOTLPTraceExporter(ctx context.Context, cfg OTLPConfig) (sdktrace.SpanExporter, error) {
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("parse OTLP endpoint: %w", err)
+	}
+
+	var exporter sdktrace.SpanExporter
+
+	switch cfg.Protocol {
+	case OTLPProtocolHTTP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpointURL(endpoint.String())}
+		if endpoint.Path == "" || endpoint.Path == "/" {
+			opts = append(opts, otlptracehttp.WithURLPath("/v1/traces"))
+		}
+
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	case OTLPProtocolGRPC:
+		exporter, err = otlptracegrpc.New(ctx, otlptracegrpc.WithEndpointURL(endpoint.String()))
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownOTLPProtocol, cfg.Protocol)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("create OTLP trace exporter: %w", err)
+	}
+
+	return exporter, nil
+}
+
+// traceFields returns the log fields that correlate a log line with the span in the context.
+func traceFields(ctx context.Context) []zap.Field {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+
+	return []zap.Field{
+		zap.String("trace_id", sc.TraceID().String()),
+		zap.String("span_id", sc.SpanID().String()),
+	}
+}
+
+// recordSpanError marks the span as failed.
+func recordSpanError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
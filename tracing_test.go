@@ -0,0 +1,244 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	eci "github.com/tvanriel/eci-prometheus-exporter"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+func newTracedApplication(t *testing.T, url string) (*eci.Application, *tracetest.SpanRecorder) {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	app := eci.NewApplication(
+		zaptest.NewLogger(t),
+		url,
+		[]eci.RegistrationNumber{*MustParseRegistrationNumber("ECI(2024)000007")},
+		"",
+		&http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport, otelhttp.WithTracerProvider(provider))},
+	)
+	app.Tracer = provider.Tracer(eci.TracerName)
+
+	return app, recorder
+}
+
+func spansByName(recorder *tracetest.SpanRecorder) map[string]sdktrace.ReadOnlySpan {
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range recorder.Ended() {
+		spans[s.Name()] = s
+	}
+
+	return spans
+}
+
+func TestApplication_FetchAndUpdateMetricsTracing(t *testing.T) {
+	t.Parallel()
+
+	server := ServerWantsCallForInitiativeID(MustParseRegistrationNumber("ECI(2024)000007"))(t)
+	defer server.Close()
+
+	app, recorder := newTracedApplication(t, server.URL)
+
+	registry := prometheus.NewRegistry()
+	app.MustRegisterWith(registry)
+
+	require.NoError(t, app.FetchAndUpdateMetrics(t.Context(), *MustParseRegistrationNumber("ECI(2024)000007")))
+
+	spans := spansByName(recorder)
+	require.Contains(t, spans, "eci.poll")
+	require.Contains(t, spans, "eci.fetch")
+	require.Contains(t, spans, "eci.decode_json")
+	require.Contains(t, spans, "eci.update_metrics")
+	require.Contains(t, spans, "HTTP GET")
+
+	poll := spans["eci.poll"]
+	assert.Equal(t, poll.SpanContext().SpanID(), spans["eci.fetch"].Parent().SpanID())
+	assert.Equal(t, spans["eci.fetch"].SpanContext().SpanID(), spans["HTTP GET"].Parent().SpanID())
+	assert.Contains(t, spans["eci.fetch"].Attributes(), attribute.Int("http.response.status_code", http.StatusOK))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	var exemplarTraceID string
+
+	for _, mf := range families {
+		if mf.GetName() != "eci_api_duration_seconds" {
+			continue
+		}
+
+		for _, b := range mf.GetMetric()[0].GetHistogram().GetBucket() {
+			if b.GetExemplar() != nil {
+				exemplarTraceID = b.GetExemplar().GetLabel()[0].GetValue()
+			}
+		}
+	}
+
+	assert.Equal(t, poll.SpanContext().TraceID().String(), exemplarTraceID)
+}
+
+func TestApplication_FetchTracingError(t *testing.T) {
+	t.Parallel()
+
+	server := BrokenAF(t)
+	defer server.Close()
+
+	app, recorder := newTracedApplication(t, server.URL)
+
+	require.Error(t, app.FetchAndUpdateMetrics(t.Context(), *MustParseRegistrationNumber("ECI(2024)000007")))
+
+	spans := spansByName(recorder)
+	require.Contains(t, spans, "eci.fetch")
+	assert.Equal(t, codes.Error, spans["eci.fetch"].Status().Code)
+	assert.Equal(t, codes.Error, spans["eci.poll"].Status().Code)
+	assert.Contains(t, spans["eci.fetch"].Attributes(), attribute.Int("http.response.status_code", http.StatusInternalServerError))
+}
+
+// traceCollectorStandIn records the names of the spans it receives over OTLP/HTTP and OTLP/gRPC.
+type traceCollectorStandIn struct {
+	coltracepb.UnimplementedTraceServiceServer
+
+	mu    sync.Mutex
+	spans []string
+}
+
+func (c *traceCollectorStandIn) Export(
+	_ context.Context,
+	req *coltracepb.ExportTraceServiceRequest,
+) (*coltracepb.ExportTraceServiceResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, rs := range req.GetResourceSpans() {
+		for _, ss := range rs.GetScopeSpans() {
+			for _, span := range ss.GetSpans() {
+				c.spans = append(c.spans, span.GetName())
+			}
+		}
+	}
+
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
+
+func (c *traceCollectorStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/traces" {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	req := &coltracepb.ExportTraceServiceRequest{}
+
+	if err := proto.Unmarshal(body, req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	_, _ = c.Export(r.Context(), req)
+
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.WriteHeader(http.StatusOK)
+}
+
+// startTraceCollector serves the stand-in with the protocol and returns its endpoint.
+func startTraceCollector(t *testing.T, standIn *traceCollectorStandIn, protocol string) string {
+	t.Helper()
+
+	if protocol == eci.OTLPProtocolHTTP {
+		server := httptest.NewServer(standIn)
+		t.Cleanup(server.Close)
+
+		return server.URL
+	}
+
+	listener, err := (&net.ListenConfig{}).Listen(t.Context(), "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	coltracepb.RegisterTraceServiceServer(server, standIn)
+
+	go func() { _ = server.Serve(listener) }()
+
+	t.Cleanup(server.Stop)
+
+	return "http://" + listener.Addr().String()
+}
+
+func TestNewOTLPTracerProvider(t *testing.T) {
+	t.Parallel()
+
+	for _, protocol := range []string{eci.OTLPProtocolHTTP, eci.OTLPProtocolGRPC} {
+		t.Run(protocol, func(t *testing.T) {
+			t.Parallel()
+
+			standIn := &traceCollectorStandIn{}
+			endpoint := startTraceCollector(t, standIn, protocol)
+
+			provider, err := eci.NewOTLPTracerProvider(t.Context(), eci.OTLPConfig{
+				Endpoint: endpoint,
+				Protocol: protocol,
+			}, []eci.RegistrationNumber{*MustParseRegistrationNumber("ECI(2024)000007")})
+			require.NoError(t, err)
+
+			_, span := provider.Tracer(eci.TracerName).Start(t.Context(), "eci.poll")
+			span.End()
+
+			require.NoError(t, provider.ForceFlush(t.Context()))
+			require.NoError(t, provider.Shutdown(t.Context()))
+
+			standIn.mu.Lock()
+			defer standIn.mu.Unlock()
+
+			assert.Equal(t, []string{"eci.poll"}, standIn.spans)
+		})
+	}
+}
+
+func TestNewOTLPTracerProvider_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		cfg     eci.OTLPConfig
+		wantErr string
+	}{
+		"unknown protocol": {
+			cfg:     eci.OTLPConfig{Endpoint: "http://localhost:4318", Protocol: "carrier-pigeon"},
+			wantErr: "unknown OTLP protocol",
+		},
+		"invalid endpoint": {
+			cfg:     eci.OTLPConfig{Endpoint: "://collector", Protocol: eci.OTLPProtocolHTTP},
+			wantErr: "parse OTLP endpoint",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := eci.NewOTLPTracerProvider(t.Context(), tt.cfg, nil)
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
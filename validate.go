@@ -0,0 +1,170 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Policies for initiatives that do not exist in the ECI register at startup.
+const (
+	ValidateFail = "fail"
+	ValidateWarn = "warn"
+	ValidateOff  = "off"
+)
+
+// MetricInitiativeValid is the name of the metric that reports whether an initiative exists.
+const MetricInitiativeValid = "eci_initiative_valid"
+
+var (
+	// ErrNotFound is returned when the ECI API does not know the initiative.
+	ErrNotFound = fmt.Errorf("%w: initiative not found", ErrNon200)
+
+	// ErrUnknownInitiatives is returned when initiatives do not exist and the validation policy is fail.
+	ErrUnknownInitiatives = errors.New("initiatives do not exist in the ECI register, check -initiatives")
+
+	// ErrUnknownValidatePolicy is returned when the validation policy is not fail, warn or off.
+	ErrUnknownValidatePolicy = errors.New("unknown initiative validation policy")
+)
+
+// ValidateInitiatives calls the ECI API for every tracked initiative and returns the initiatives that do not exist.
+// Initiatives that cannot be checked because of a transient error are assumed to exist. The result is exposed in
+// InitiativeValid.
+func (a *Application) ValidateInitiatives(ctx context.Context, timeout time.Duration) []RegistrationNumber {
+	initiatives := a.TrackedInitiatives()
+	unknown := make([]bool, len(initiatives))
+
+	var wg sync.WaitGroup
+
+	for i, registrationNumber := range initiatives {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			logger := a.Logger.With(zap.String("initiative_id", registrationNumber.String()))
+
+			err := a.validate(ctx, registrationNumber, timeout)
+
+			switch {
+			case err == nil:
+				a.InitiativeValid.WithLabelValues(registrationNumber.String()).Set(1)
+			case errors.Is(err, ErrNotFound):
+				a.InitiativeValid.WithLabelValues(registrationNumber.String()).Set(0)
+				logger.Warn("Initiative does not exist in the ECI register")
+
+				unknown[i] = true
+			default:
+				logger.Warn("Cannot validate initiative, assuming it exists", zap.Error(err))
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	var invalid []RegistrationNumber
+
+	for i, registrationNumber := range initiatives {
+		if unknown[i] {
+			invalid = append(invalid, registrationNumber)
+		}
+	}
+
+	slices.SortFunc(invalid, func(a, b RegistrationNumber) int { return strings.Compare(a.String(), b.String()) })
+
+	return invalid
+}
+
+// ApplyValidatePolicy validates the initiatives according to the policy. With fail it returns
+// [ErrUnknownInitiatives] when an initiative does not exist, with warn unknown initiatives are only logged and
+// reported as invalid.
+func (a *Application) ApplyValidatePolicy(ctx context.Context, policy string, timeout time.Duration) error {
+	err := CheckValidatePolicy(policy)
+	if err != nil || policy == ValidateOff {
+		return err
+	}
+
+	invalid := a.ValidateInitiatives(ctx, timeout)
+	if len(invalid) == 0 || policy == ValidateWarn {
+		return nil
+	}
+
+	ids := make([]string, 0, len(invalid))
+	for _, registrationNumber := range invalid {
+		ids = append(ids, registrationNumber.String())
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnknownInitiatives, strings.Join(ids, ", "))
+}
+
+// CheckValidatePolicy returns [ErrUnknownValidatePolicy] when the policy is not fail, warn or off.
+func CheckValidatePolicy(policy string) error {
+	switch policy {
+	case ValidateFail, ValidateWarn, ValidateOff:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownValidatePolicy, policy)
+	}
+}
+
+// validate requests the details of the initiative without going through the cache and circuit breaker, so the
+// first poll is not affected. It returns [ErrNotFound] when the initiative does not exist.
+func (a *Application) validate(ctx context.Context, registrationNumber RegistrationNumber, timeout time.Duration) error {
+	ctx, span := a.Tracer.Start(ctx, "eci.validate", trace.WithAttributes(
+		attribute.String("eci.initiative_id", registrationNumber.String()),
+	))
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if a.Scheduler != nil && a.Scheduler.Limiter != nil {
+		err := a.Scheduler.Limiter.Wait(ctx)
+		if err != nil {
+			recordSpanError(span, err)
+
+			return fmt.Errorf("wait for rate limiter: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.eciAPIURL(registrationNumber), nil)
+	if err != nil {
+		recordSpanError(span, err)
+
+		return fmt.Errorf("make request: %w", err)
+	}
+
+	resp, err := a.HTTPClient.Do(req)
+	if err != nil {
+		recordSpanError(span, err)
+
+		return fmt.Errorf("doing request: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // don't really care.
+
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		recordSpanError(span, ErrNotFound)
+
+		return ErrNotFound
+	default:
+		recordSpanError(span, ErrNon200)
+
+		return fmt.Errorf("%w: status %d", ErrNon200, resp.StatusCode)
+	}
+}
@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	eci "github.com/tvanriel/eci-prometheus-exporter"
+	"go.uber.org/zap/zaptest"
+)
+
+// registerServer answers the details of ECI(2024)000007 and 404 for every other initiative, or 503 for ECI(2024)000009.
+func registerServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/core/api/register/details/2024/000007":
+			_, _ = w.Write([]byte(defaultResponse))
+		case "/core/api/register/details/2024/000009":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestApplication_ApplyValidatePolicy(t *testing.T) {
+	t.Parallel()
+
+	initiatives := []eci.RegistrationNumber{
+		*MustParseRegistrationNumber("ECI(2024)000007"),
+		*MustParseRegistrationNumber("ECI(2024)000008"),
+		*MustParseRegistrationNumber("ECI(2024)000009"),
+	}
+
+	tests := map[string]struct {
+		policy      string
+		initiatives []eci.RegistrationNumber
+		wantErr     assert.ErrorAssertionFunc
+		wantValid   map[string]float64
+	}{
+		"fail": {
+			policy:      eci.ValidateFail,
+			initiatives: initiatives,
+			wantErr:     errContains("ECI(2024)000008"),
+			wantValid:   map[string]float64{"ECI(2024)000007": 1, "ECI(2024)000008": 0},
+		},
+		"fail without unknown initiatives": {
+			policy:      eci.ValidateFail,
+			initiatives: []eci.RegistrationNumber{initiatives[0], initiatives[2]},
+			wantErr:     assert.NoError,
+			wantValid:   map[string]float64{"ECI(2024)000007": 1},
+		},
+		"warn": {
+			policy:      eci.ValidateWarn,
+			initiatives: initiatives,
+			wantErr:     assert.NoError,
+			wantValid:   map[string]float64{"ECI(2024)000007": 1, "ECI(2024)000008": 0},
+		},
+		"off": {
+			policy:      eci.ValidateOff,
+			initiatives: initiatives,
+			wantErr:     assert.NoError,
+			wantValid:   map[string]float64{},
+		},
+		"unknown policy": {
+			policy:      "maybe",
+			initiatives: initiatives,
+			wantErr:     errContains("unknown initiative validation policy"),
+			wantValid:   map[string]float64{},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server := registerServer(t)
+			app := eci.NewApplication(zaptest.NewLogger(t), server.URL, tt.initiatives, "", http.DefaultClient)
+
+			err := app.ApplyValidatePolicy(t.Context(), tt.policy, time.Second)
+			tt.wantErr(t, err)
+
+			assert.Equal(t, len(tt.wantValid), testutil.CollectAndCount(app.InitiativeValid))
+
+			for id, want := range tt.wantValid {
+				assert.InDelta(t, want, testutil.ToFloat64(app.InitiativeValid.WithLabelValues(id)), 0, id)
+			}
+		})
+	}
+}
+
+func TestApplication_FetchNotFound(t *testing.T) {
+	t.Parallel()
+
+	server := registerServer(t)
+	known := *MustParseRegistrationNumber("ECI(2024)000007")
+	unknown := *MustParseRegistrationNumber("ECI(2024)000008")
+	app := eci.NewApplication(zaptest.NewLogger(t), server.URL, []eci.RegistrationNumber{known, unknown}, "", http.DefaultClient)
+
+	err := app.FetchAndUpdateMetrics(t.Context(), unknown)
+	require.ErrorIs(t, err, eci.ErrNotFound)
+	require.ErrorIs(t, err, eci.ErrNon200)
+	assert.Zero(t, testutil.ToFloat64(app.InitiativeValid.WithLabelValues(unknown.String())))
+
+	require.NoError(t, app.FetchAndUpdateMetrics(t.Context(), known))
+	assert.InDelta(t, 1, testutil.ToFloat64(app.InitiativeValid.WithLabelValues(known.String())), 0)
+}
+
+func TestCheckValidatePolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		policy  string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		"fail":    {policy: eci.ValidateFail, wantErr: assert.NoError},
+		"warn":    {policy: eci.ValidateWarn, wantErr: assert.NoError},
+		"off":     {policy: eci.ValidateOff, wantErr: assert.NoError},
+		"unknown": {policy: "maybe", wantErr: errContains(`unknown initiative validation policy: "maybe"`)},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.wantErr(t, eci.CheckValidatePolicy(tt.policy))
+		})
+	}
+}
@@ -0,0 +1,308 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+)
+
+// Errors of an invalid web configuration.
+var (
+	ErrMissingCertificate = errors.New("tls_server_config needs both cert_file and key_file")
+	ErrUnknownClientAuth  = errors.New("unknown client_auth_type")
+	ErrUnknownTLSVersion  = errors.New("unknown TLS version")
+	ErrMissingClientCA    = errors.New("client_auth_type requires client_ca_file")
+	ErrEmptyBearerToken   = errors.New("bearer tokens must not be empty")
+)
+
+// WebConfig is the web configuration file of the exporter, in the format of the Prometheus exporter-toolkit with
+// additional bearer tokens and separate credentials for the admin API.
+type WebConfig struct {
+	TLSServerConfig     *TLSServerConfig  `yaml:"tls_server_config"`
+	BasicAuthUsers      map[string]string `yaml:"basic_auth_users"`
+	BearerTokens        []string          `yaml:"bearer_tokens"`
+	AdminBasicAuthUsers map[string]string `yaml:"admin_basic_auth_users"`
+	AdminBearerTokens   []string          `yaml:"admin_bearer_tokens"`
+
+	mu    sync.Mutex
+	valid map[[sha256.Size]byte]struct{}
+}
+
+// TLSServerConfig configures TLS for the endpoints of the exporter.
+type TLSServerConfig struct {
+	CertFile       string `yaml:"cert_file"`
+	KeyFile        string `yaml:"key_file"`
+	ClientAuthType string `yaml:"client_auth_type"`
+	ClientCAFile   string `yaml:"client_ca_file"`
+	MinVersion     string `yaml:"min_version"`
+}
+
+// LoadWebConfig reads the web configuration file. Unknown fields and empty bearer tokens, which would authenticate
+// an empty Authorization: Bearer header, are rejected.
+func LoadWebConfig(path string) (*WebConfig, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read web config: %w", err)
+	}
+
+	c := &WebConfig{}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+
+	err = decoder.Decode(c)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse web config: %w", err)
+	}
+
+	if slices.Contains(c.BearerTokens, "") || slices.Contains(c.AdminBearerTokens, "") {
+		return nil, ErrEmptyBearerToken
+	}
+
+	return c, nil
+}
+
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"":                           tls.NoClientCert,
+	"NoClientCert":               tls.NoClientCert,
+	"RequestClientCert":          tls.RequestClientCert,
+	"RequireAnyClientCert":       tls.RequireAnyClientCert,
+	"VerifyClientCertIfGiven":    tls.VerifyClientCertIfGiven,
+	"RequireAndVerifyClientCert": tls.RequireAndVerifyClientCert,
+}
+
+var tlsVersions = map[string]uint16{
+	"":      tls.VersionTLS12,
+	"TLS12": tls.VersionTLS12,
+	"TLS13": tls.VersionTLS13,
+}
+
+// TLSConfig returns the TLS configuration of the server, or nil when TLS is not configured. The certificate is read
+// again when its files change, so renewed certificates are picked up without a restart.
+func (c *WebConfig) TLSConfig() (*tls.Config, error) {
+	cfg := c.TLSServerConfig
+	if cfg == nil {
+		return nil, nil //nolint:nilnil // no TLS is not an error.
+	}
+
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, ErrMissingCertificate
+	}
+
+	clientAuth, ok := clientAuthTypes[cfg.ClientAuthType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownClientAuth, cfg.ClientAuthType)
+	}
+
+	minVersion, ok := tlsVersions[cfg.MinVersion]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTLSVersion, cfg.MinVersion)
+	}
+
+	reloader := &certificateReloader{CertFile: cfg.CertFile, KeyFile: cfg.KeyFile}
+
+	_, err := reloader.GetCertificate(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:     minVersion,
+		ClientAuth:     clientAuth,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	if cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read client CA: %w", err)
+		}
+
+		tlsConfig.ClientCAs = x509.NewCertPool()
+		if !tlsConfig.ClientCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCABundle, cfg.ClientCAFile)
+		}
+	} else if clientAuth == tls.VerifyClientCertIfGiven || clientAuth == tls.RequireAndVerifyClientCert {
+		return nil, ErrMissingClientCA
+	}
+
+	return tlsConfig, nil
+}
+
+// certificateReloader loads a certificate and reloads it when the modification time of its files changes.
+type certificateReloader struct {
+	CertFile string
+	KeyFile  string
+
+	mu       sync.Mutex
+	cert     *tls.Certificate
+	modified [2]time.Time
+}
+
+// GetCertificate implements [tls.Config.GetCertificate].
+func (r *certificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var modified [2]time.Time
+
+	for i, path := range []string{r.CertFile, r.KeyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("stat certificate: %w", err)
+		}
+
+		modified[i] = info.ModTime()
+	}
+
+	if r.cert != nil && modified == r.modified {
+		return r.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.CertFile, r.KeyFile)
+	if err != nil {
+		if r.cert != nil {
+			// Keep serving the previous certificate while the files are being replaced.
+			return r.cert, nil
+		}
+
+		return nil, fmt.Errorf("load certificate: %w", err)
+	}
+
+	r.cert = &cert
+	r.modified = modified
+
+	return r.cert, nil
+}
+
+// dummyHash is compared against for unknown users, so they cannot be told apart by the response time.
+var dummyHash = []byte("$2a$10$RcIehCdWSR/wFf3oCsJ31ekBx1J.PmF6d8DmYWgpilJnR7f8M9IUK")
+
+// Authenticates reports whether the configuration requires authentication.
+func (c *WebConfig) Authenticates() bool {
+	return len(c.BasicAuthUsers) > 0 || len(c.BearerTokens) > 0
+}
+
+// AuthenticatesAdmin reports whether the configuration has credentials for the admin API.
+func (c *WebConfig) AuthenticatesAdmin() bool {
+	return len(c.AdminBasicAuthUsers) > 0 || len(c.AdminBearerTokens) > 0
+}
+
+// Middleware requires basic auth or a bearer token when the configuration has users or tokens.
+func (c *WebConfig) Middleware(next http.Handler) http.Handler {
+	if !c.Authenticates() {
+		return next
+	}
+
+	return c.require(next, c.BasicAuthUsers, c.BearerTokens)
+}
+
+// AdminMiddleware requires the admin users or tokens. The credentials of [WebConfig.Middleware] are not accepted, and
+// every request is rejected when the configuration has no admin credentials.
+func (c *WebConfig) AdminMiddleware(next http.Handler) http.Handler {
+	return c.require(next, c.AdminBasicAuthUsers, c.AdminBearerTokens)
+}
+
+// MiddlewareWithAdminAPI requires the credentials of [WebConfig.AdminMiddleware] under [AdminAPIPrefix] and those of
+// [WebConfig.Middleware] on the other routes. The admin API changes what is polled, so the credentials of the
+// scrapers are not enough.
+func (c *WebConfig) MiddlewareWithAdminAPI(next http.Handler) http.Handler {
+	routes := http.NewServeMux()
+	routes.Handle("/", c.Middleware(next))
+	routes.Handle(AdminAPIPrefix, c.AdminMiddleware(next))
+
+	return routes
+}
+
+// require serves the request when it authenticates with one of the users or tokens.
+func (c *WebConfig) require(next http.Handler, users map[string]string, tokens []string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c.authenticated(r, users, tokens) {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		if len(users) > 0 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="`+ServiceName+`"`)
+		} else {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+		}
+
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	})
+}
+
+func (c *WebConfig) authenticated(r *http.Request, users map[string]string, tokens []string) bool {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		// Compare digests of equal length, so the comparison does not leak the length of the tokens.
+		presented := sha256.Sum256([]byte(token))
+		valid := 0
+
+		for _, t := range tokens {
+			configured := sha256.Sum256([]byte(t))
+			valid |= subtle.ConstantTimeCompare(presented[:], configured[:])
+		}
+
+		return valid == 1
+	}
+
+	user, password, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+
+	hash, known := users[user]
+	if !known {
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+
+		return false
+	}
+
+	return c.checkPassword(user, hash, password)
+}
+
+// checkPassword compares the password with the bcrypt hash. Valid credentials are cached, because bcrypt is
+// deliberately slow and Prometheus authenticates every scrape.
+func (c *WebConfig) checkPassword(user, hash, password string) bool {
+	key := sha256.Sum256([]byte(user + "\x00" + hash + "\x00" + password))
+
+	c.mu.Lock()
+	_, ok := c.valid[key]
+	c.mu.Unlock()
+
+	if ok {
+		return true
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.valid == nil {
+		c.valid = map[[sha256.Size]byte]struct{}{}
+	}
+
+	c.valid[key] = struct{}{}
+
+	return true
+}
@@ -0,0 +1,349 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	eci "github.com/tvanriel/eci-prometheus-exporter"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestLoadWebConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		content string
+		want    *eci.WebConfig
+		err     assert.ErrorAssertionFunc
+	}{
+		"empty": {
+			content: "",
+			want:    &eci.WebConfig{},
+			err:     assert.NoError,
+		},
+		"full": {
+			content: `
+tls_server_config:
+  cert_file: server.crt
+  key_file: server.key
+  min_version: TLS13
+basic_auth_users:
+  prometheus: $2y$10$hash
+bearer_tokens:
+  - secret
+admin_basic_auth_users:
+  admin: $2y$10$adminhash
+admin_bearer_tokens:
+  - admin-secret
+`,
+			want: &eci.WebConfig{
+				TLSServerConfig:     &eci.TLSServerConfig{CertFile: "server.crt", KeyFile: "server.key", MinVersion: "TLS13"},
+				BasicAuthUsers:      map[string]string{"prometheus": "$2y$10$hash"},
+				BearerTokens:        []string{"secret"},
+				AdminBasicAuthUsers: map[string]string{"admin": "$2y$10$adminhash"},
+				AdminBearerTokens:   []string{"admin-secret"},
+			},
+			err: assert.NoError,
+		},
+		"unknown field": {
+			content: "basic_auth_user:\n  prometheus: hash\n",
+			err:     errContains("basic_auth_user"),
+		},
+		"empty bearer token": {
+			content: "bearer_tokens:\n  - secret\n  - \"\"\n",
+			err:     errIs(eci.ErrEmptyBearerToken),
+		},
+		"empty admin bearer token": {
+			content: "admin_bearer_tokens:\n  - \"\"\n",
+			err:     errIs(eci.ErrEmptyBearerToken),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "web.yml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+			got, err := eci.LoadWebConfig(path)
+			if !tt.err(t, err) || err != nil {
+				return
+			}
+
+			assert.Equal(t, tt.want.TLSServerConfig, got.TLSServerConfig)
+			assert.Equal(t, tt.want.BasicAuthUsers, got.BasicAuthUsers)
+			assert.Equal(t, tt.want.BearerTokens, got.BearerTokens)
+			assert.Equal(t, tt.want.AdminBasicAuthUsers, got.AdminBasicAuthUsers)
+			assert.Equal(t, tt.want.AdminBearerTokens, got.AdminBearerTokens)
+		})
+	}
+}
+
+func TestWebConfig_Middleware(t *testing.T) {
+	t.Parallel()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("hunter2"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) })
+
+	tests := map[string]struct {
+		config    *eci.WebConfig
+		prepare   func(r *http.Request)
+		want      int
+		challenge string
+	}{
+		"no authentication configured": {
+			config:  &eci.WebConfig{},
+			prepare: func(*http.Request) {},
+			want:    http.StatusNoContent,
+		},
+		"valid password": {
+			config:  &eci.WebConfig{BasicAuthUsers: map[string]string{"prometheus": string(hash)}},
+			prepare: func(r *http.Request) { r.SetBasicAuth("prometheus", "hunter2") },
+			want:    http.StatusNoContent,
+		},
+		"wrong password": {
+			config:    &eci.WebConfig{BasicAuthUsers: map[string]string{"prometheus": string(hash)}},
+			prepare:   func(r *http.Request) { r.SetBasicAuth("prometheus", "hunter3") },
+			want:      http.StatusUnauthorized,
+			challenge: `Basic realm="eci-prometheus-exporter"`,
+		},
+		"unknown user": {
+			config:    &eci.WebConfig{BasicAuthUsers: map[string]string{"prometheus": string(hash)}},
+			prepare:   func(r *http.Request) { r.SetBasicAuth("grafana", "hunter2") },
+			want:      http.StatusUnauthorized,
+			challenge: `Basic realm="eci-prometheus-exporter"`,
+		},
+		"missing credentials": {
+			config:    &eci.WebConfig{BasicAuthUsers: map[string]string{"prometheus": string(hash)}},
+			prepare:   func(*http.Request) {},
+			want:      http.StatusUnauthorized,
+			challenge: `Basic realm="eci-prometheus-exporter"`,
+		},
+		"valid token": {
+			config:  &eci.WebConfig{BasicAuthUsers: map[string]string{"prometheus": string(hash)}, BearerTokens: []string{"secret"}},
+			prepare: func(r *http.Request) { r.Header.Set("Authorization", "Bearer secret") },
+			want:    http.StatusNoContent,
+		},
+		"wrong token": {
+			config:    &eci.WebConfig{BearerTokens: []string{"secret"}},
+			prepare:   func(r *http.Request) { r.Header.Set("Authorization", "Bearer guess") },
+			want:      http.StatusUnauthorized,
+			challenge: "Bearer",
+		},
+		"second token": {
+			config:  &eci.WebConfig{BearerTokens: []string{"secret", "other"}},
+			prepare: func(r *http.Request) { r.Header.Set("Authorization", "Bearer other") },
+			want:    http.StatusNoContent,
+		},
+		"token prefix": {
+			config:    &eci.WebConfig{BearerTokens: []string{"secret"}},
+			prepare:   func(r *http.Request) { r.Header.Set("Authorization", "Bearer secre") },
+			want:      http.StatusUnauthorized,
+			challenge: "Bearer",
+		},
+		"empty token": {
+			config:    &eci.WebConfig{BearerTokens: []string{"secret"}},
+			prepare:   func(r *http.Request) { r.Header.Set("Authorization", "Bearer ") },
+			want:      http.StatusUnauthorized,
+			challenge: "Bearer",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			tt.prepare(req)
+
+			rec := httptest.NewRecorder()
+			tt.config.Middleware(ok).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.want, rec.Code)
+			assert.Equal(t, tt.challenge, rec.Header().Get("WWW-Authenticate"))
+		})
+	}
+}
+
+func TestWebConfig_MiddlewareWithAdminAPI(t *testing.T) {
+	t.Parallel()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("hunter2"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) })
+
+	config := &eci.WebConfig{
+		BearerTokens:        []string{"secret"},
+		AdminBasicAuthUsers: map[string]string{"admin": string(hash)},
+		AdminBearerTokens:   []string{"admin-secret"},
+	}
+
+	tests := map[string]struct {
+		config  *eci.WebConfig
+		path    string
+		prepare func(r *http.Request)
+		want    int
+	}{
+		"metrics with token": {
+			config:  config,
+			path:    "/metrics",
+			prepare: func(r *http.Request) { r.Header.Set("Authorization", "Bearer secret") },
+			want:    http.StatusNoContent,
+		},
+		"metrics with admin token": {
+			config:  config,
+			path:    "/metrics",
+			prepare: func(r *http.Request) { r.Header.Set("Authorization", "Bearer admin-secret") },
+			want:    http.StatusUnauthorized,
+		},
+		"admin API with admin token": {
+			config:  config,
+			path:    "/api/v1/admin/initiatives",
+			prepare: func(r *http.Request) { r.Header.Set("Authorization", "Bearer admin-secret") },
+			want:    http.StatusNoContent,
+		},
+		"admin API with admin password": {
+			config:  config,
+			path:    "/api/v1/admin/initiatives",
+			prepare: func(r *http.Request) { r.SetBasicAuth("admin", "hunter2") },
+			want:    http.StatusNoContent,
+		},
+		"admin API with token": {
+			config:  config,
+			path:    "/api/v1/admin/initiatives",
+			prepare: func(r *http.Request) { r.Header.Set("Authorization", "Bearer secret") },
+			want:    http.StatusUnauthorized,
+		},
+		"refresh with token": {
+			config:  config,
+			path:    "/api/v1/refresh",
+			prepare: func(r *http.Request) { r.Header.Set("Authorization", "Bearer secret") },
+			want:    http.StatusUnauthorized,
+		},
+		"admin API through another route": {
+			config:  config,
+			path:    "/metrics/../api/v1/admin/initiatives",
+			prepare: func(r *http.Request) { r.Header.Set("Authorization", "Bearer secret") },
+			want:    http.StatusTemporaryRedirect,
+		},
+		"admin API without admin credentials": {
+			config:  &eci.WebConfig{},
+			path:    "/api/v1/admin/initiatives",
+			prepare: func(*http.Request) {},
+			want:    http.StatusUnauthorized,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			tt.prepare(req)
+
+			rec := httptest.NewRecorder()
+			tt.config.MiddlewareWithAdminAPI(ok).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.want, rec.Code)
+		})
+	}
+}
+
+func TestWebConfig_TLSConfigErrors(t *testing.T) {
+	t.Parallel()
+
+	certFile, keyFile := selfSignedCertificate(t, t.TempDir(), "server")
+
+	tests := map[string]struct {
+		config *eci.TLSServerConfig
+		err    assert.ErrorAssertionFunc
+	}{
+		"missing key": {
+			config: &eci.TLSServerConfig{CertFile: certFile},
+			err:    errIs(eci.ErrMissingCertificate),
+		},
+		"unknown client auth": {
+			config: &eci.TLSServerConfig{CertFile: certFile, KeyFile: keyFile, ClientAuthType: "Sometimes"},
+			err:    errContains("unknown client_auth_type"),
+		},
+		"unknown version": {
+			config: &eci.TLSServerConfig{CertFile: certFile, KeyFile: keyFile, MinVersion: "SSL3"},
+			err:    errContains("unknown TLS version"),
+		},
+		"verification without CA": {
+			config: &eci.TLSServerConfig{CertFile: certFile, KeyFile: keyFile, ClientAuthType: "RequireAndVerifyClientCert"},
+			err:    errIs(eci.ErrMissingClientCA),
+		},
+		"unreadable certificate": {
+			config: &eci.TLSServerConfig{CertFile: keyFile, KeyFile: keyFile},
+			err:    errContains("load certificate"),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := (&eci.WebConfig{TLSServerConfig: tt.config}).TLSConfig()
+			tt.err(t, err)
+		})
+	}
+}
+
+func TestWebConfig_TLSConfigReload(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	certFile, keyFile := selfSignedCertificate(t, dir, "server")
+
+	tlsConfig, err := (&eci.WebConfig{TLSServerConfig: &eci.TLSServerConfig{CertFile: certFile, KeyFile: keyFile}}).TLSConfig()
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.Listener = tls.NewListener(server.Listener, tlsConfig)
+	server.Start()
+	defer server.Close()
+
+	// servedCertificate connects with a fresh client that trusts the certificate currently on disk.
+	servedCertificate := func() error {
+		pem, err := os.ReadFile(certFile)
+		require.NoError(t, err)
+
+		pool := x509.NewCertPool()
+		require.True(t, pool.AppendCertsFromPEM(pem))
+
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}}}
+
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "https"+server.URL[len("http"):], nil)
+		require.NoError(t, err)
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+
+		return resp.Body.Close()
+	}
+
+	require.NoError(t, servedCertificate())
+
+	selfSignedCertificate(t, dir, "server")
+
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, later, later))
+	require.NoError(t, os.Chtimes(keyFile, later, later))
+
+	assert.NoError(t, servedCertificate(), "the renewed certificate is served")
+}
@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: EUPL-1.2
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"text/template"
+	"time"
+)
+
+// DefaultWebhookTemplate renders the event as JSON.
+const DefaultWebhookTemplate = `{{ json . }}`
+
+// Webhook delivers events as an HTTP POST with a payload rendered from a Go template.
+type Webhook struct {
+	URL          string
+	Template     *template.Template
+	HTTPClient   *http.Client
+	Retries      int
+	RetryBackoff time.Duration
+}
+
+// ParseWebhookTemplate parses a payload template. The template is executed with an [Event]
+// and can use the json function to encode values.
+func ParseWebhookTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("webhook").Funcs(template.FuncMap{
+		"json": func(v any) (string, error) {
+			b, err := json.Marshal(v)
+
+			return string(b), err
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse webhook template: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// Deliver posts the event to the webhook, retrying failed deliveries.
+func (w *Webhook) Deliver(ctx context.Context, event Event) error {
+	var payload bytes.Buffer
+
+	err := w.Template.Execute(&payload, event)
+	if err != nil {
+		return fmt.Errorf("render webhook payload: %w", err)
+	}
+
+	for attempt := 0; attempt <= w.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("deliver webhook: %w", ctx.Err())
+			case <-time.After(w.RetryBackoff * time.Duration(attempt)):
+			}
+		}
+
+		var req *http.Request
+
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(payload.Bytes()))
+		if err != nil {
+			return fmt.Errorf("make request: %w", err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+
+		err = doSinkRequest(w.HTTPClient, req)
+		if err == nil {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("deliver webhook: %w", err)
+}